@@ -2,6 +2,7 @@ package cache
 
 import (
 	"sync"
+	"time"
 
 	"github.com/Prasang-money/searchSvc/models"
 )
@@ -13,24 +14,54 @@ type Cache struct {
 	dll   *DoublyLinkedList
 	size  int
 	cap   int
+	ttl   time.Duration
 	mutex sync.RWMutex // Mutex for thread-safe operations
+
+	// counters reported by Stats
+	hits, misses, evictions, expirations uint64
+
+	// now is swapped by tests to control expiry
+	now func() time.Time
+}
+
+// Entry is a cached country with the time it was stored and the time it
+// expires, which is zero when the cache has no TTL.
+type Entry struct {
+	Value     models.CountryMetadata
+	StoredAt  time.Time
+	ExpiresAt time.Time
 }
 
 func NewCache(capacity int) *Cache {
+	return NewCacheWithTTL(capacity, 0)
+}
+
+// NewCacheWithTTL returns a cache whose entries expire ttl after they are
+// set. Entries of a cache with a zero ttl only leave it when evicted.
+func NewCacheWithTTL(capacity int, ttl time.Duration) *Cache {
 	return &Cache{
 		data:  make(map[string]*Node),
 		cap:   capacity,
+		ttl:   ttl,
 		dll:   &DoublyLinkedList{},
 		mutex: sync.RWMutex{},
+		now:   time.Now,
 	}
 }
 
+// TTL returns how long entries stay in the cache, or zero if they never
+// expire.
+func (cache *Cache) TTL() time.Duration {
+	return cache.ttl
+}
+
 func (cache *Cache) Set(key string, value *models.CountryMetadata) {
 	cache.mutex.Lock()
 	defer cache.mutex.Unlock()
 
 	if node, exists := cache.data[key]; exists {
 		node.value = value
+		node.storedAt = cache.now()
 		// move existing node to front (no size change)
 		if cache.dll.head != node {
 			cache.dll.remove(node)
@@ -39,6 +70,7 @@ func (cache *Cache) Set(key string, value *models.CountryMetadata) {
 		return
 	}
 	cache.data[key] = NewNode(key, value)
+	cache.data[key].storedAt = cache.now()
 	cache.dll.addToFront(cache.data[key])
 	cache.size++
 	if cache.size > cache.cap {
@@ -52,20 +84,73 @@ func (cache *Cache) removeOldest() {
 		delete(cache.data, cache.dll.tail.key)
 		cache.dll.remove(cache.dll.tail)
 		cache.size--
+		cache.evictions++
 	}
 }
 
 func (cache *Cache) Get(key string) (models.CountryMetadata, bool) {
+	entry, ok := cache.GetEntry(key)
+	return entry.Value, ok
+}
+
+// GetEntry returns the cached country for key with the time it was stored.
+// Expired entries are removed and reported as missing.
+func (cache *Cache) GetEntry(key string) (Entry, bool) {
 	cache.mutex.Lock()
 	defer cache.mutex.Unlock()
 
-	if cache.data[key] == nil {
-		return models.CountryMetadata{}, false
+	node := cache.data[key]
+	if node == nil {
+		cache.misses++
+		return Entry{}, false
+	}
+	entry := Entry{Value: *node.value, StoredAt: node.storedAt}
+	if cache.ttl > 0 {
+		entry.ExpiresAt = node.storedAt.Add(cache.ttl)
+		if !cache.now().Before(entry.ExpiresAt) {
+			delete(cache.data, key)
+			cache.dll.remove(node)
+			cache.size--
+			cache.expirations++
+			cache.misses++
+			return Entry{}, false
+		}
 	}
 	//fmt.Println("Cache hit for key:", key)
-	cache.dll.remove(cache.data[key])
-	cache.dll.addToFront(cache.data[key])
-	return *cache.data[key].value, true
+	cache.hits++
+	cache.dll.remove(node)
+	cache.dll.addToFront(node)
+	return entry, true
+}
+
+// Stats reports the size of the cache and how it was used since it was
+// created.
+func (cache *Cache) Stats() models.CacheStats {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	return models.CacheStats{
+		Size:        cache.size,
+		Capacity:    cache.cap,
+		TTLSeconds:  int64(cache.ttl / time.Second),
+		Hits:        cache.hits,
+		Misses:      cache.misses,
+		Evictions:   cache.evictions,
+		Expirations: cache.expirations,
+	}
+}
+
+// Purge removes every entry and returns how many there were. The usage
+// counters are kept.
+func (cache *Cache) Purge() int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	purged := cache.size
+	cache.data = make(map[string]*Node)
+	cache.dll = &DoublyLinkedList{}
+	cache.size = 0
+	return purged
 }
 
 type DoublyLinkedList struct {
@@ -99,10 +184,11 @@ func (dll *DoublyLinkedList) remove(node *Node) {
 }
 
 type Node struct {
-	key   string
-	value *models.CountryMetadata
-	prev  *Node
-	next  *Node
+	key      string
+	value    *models.CountryMetadata
+	storedAt time.Time
+	prev     *Node
+	next     *Node
 }
 
 func NewNode(key string, value *models.CountryMetadata) *Node {
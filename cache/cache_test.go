@@ -3,6 +3,7 @@ package cache
 import (
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/Prasang-money/searchSvc/models"
 )
@@ -281,3 +282,86 @@ func TestZeroCapacity(t *testing.T) {
 		t.Error("Zero capacity cache should not return items")
 	}
 }
+
+// Test expiry of entries
+func TestTTL(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewCacheWithTTL(3, time.Hour)
+	cache.now = func() time.Time { return now }
+
+	cache.Set("key", &models.CountryMetadata{Name: "Test"})
+
+	now = now.Add(59 * time.Minute)
+	entry, exists := cache.GetEntry("key")
+	if !exists {
+		t.Fatal("Entry should not expire before its TTL")
+	}
+	if entry.Value.Name != "Test" {
+		t.Errorf("Expected name Test, got %s", entry.Value.Name)
+	}
+	if want := now.Add(-59 * time.Minute); !entry.StoredAt.Equal(want) {
+		t.Errorf("Expected stored at %v, got %v", want, entry.StoredAt)
+	}
+	if want := entry.StoredAt.Add(time.Hour); !entry.ExpiresAt.Equal(want) {
+		t.Errorf("Expected expiry at %v, got %v", want, entry.ExpiresAt)
+	}
+
+	// Setting the key again restarts its TTL
+	cache.Set("key", &models.CountryMetadata{Name: "Test"})
+	now = now.Add(59 * time.Minute)
+	if _, exists := cache.Get("key"); !exists {
+		t.Error("Updated entry should not expire before its new TTL")
+	}
+
+	now = now.Add(time.Minute)
+	if _, exists := cache.Get("key"); exists {
+		t.Error("Entry should expire after its TTL")
+	}
+	if cache.size != 0 {
+		t.Errorf("Expected expired entry to be removed, size is %d", cache.size)
+	}
+
+	// Without a TTL entries do not expire
+	cache = NewCache(3)
+	cache.Set("key", &models.CountryMetadata{Name: "Test"})
+	entry, exists = cache.GetEntry("key")
+	if !exists || !entry.ExpiresAt.IsZero() {
+		t.Errorf("Expected entry without expiry, got %+v, %v", entry, exists)
+	}
+}
+
+func TestStatsAndPurge(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewCacheWithTTL(2, time.Hour)
+	cache.now = func() time.Time { return now }
+
+	cache.Set("a", &models.CountryMetadata{Name: "A"})
+	cache.Set("b", &models.CountryMetadata{Name: "B"})
+	cache.Set("c", &models.CountryMetadata{Name: "C"}) // evicts a
+	cache.Get("a")
+	cache.Get("b")
+	now = now.Add(time.Hour)
+	cache.Get("c") // expired
+
+	want := models.CacheStats{Size: 1, Capacity: 2, TTLSeconds: 3600, Hits: 1, Misses: 2, Evictions: 1, Expirations: 1}
+	if got := cache.Stats(); got != want {
+		t.Errorf("Expected stats %+v, got %+v", want, got)
+	}
+
+	if purged := cache.Purge(); purged != 1 {
+		t.Errorf("Expected 1 purged entry, got %d", purged)
+	}
+	if _, exists := cache.Get("b"); exists {
+		t.Error("Purged entry should be gone")
+	}
+	stats := cache.Stats()
+	if stats.Size != 0 || stats.Misses != 3 {
+		t.Errorf("Expected an empty cache keeping its counters, got %+v", stats)
+	}
+
+	// the cache is usable after a purge
+	cache.Set("d", &models.CountryMetadata{Name: "D"})
+	if value, exists := cache.Get("d"); !exists || value.Name != "D" {
+		t.Error("Expected to find d after a purge")
+	}
+}
@@ -0,0 +1,283 @@
+// Package client is a Go client for the REST API of searchSvc. It speaks v2
+// of the API, so countries come back in the full view.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Prasang-money/searchSvc/models"
+)
+
+// Defaults of a Client.
+const (
+	DefaultTimeout = 10 * time.Second
+	DefaultRetries = 2
+	DefaultBackoff = 200 * time.Millisecond
+)
+
+// apiPrefix is the version of the API the client speaks.
+const apiPrefix = "/api/v2"
+
+// maxErrorBody bounds the error documents read from the service.
+const maxErrorBody = 1 << 20
+
+// Client calls a searchSvc instance. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	timeout    time.Duration
+	retries    int
+	backoff    time.Duration
+	authorize  func(*http.Request)
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sends requests through hc instead of a new http.Client.
+// The timeout of the Client still applies.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithTimeout bounds every attempt of a call, including reading the
+// response. Zero means no timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithRetries retries calls failing with a network error, a timeout or an
+// unavailable upstream up to n times, waiting backoff before the first
+// retry and twice as long before each next one.
+func WithRetries(n int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = max(n, 0)
+		c.backoff = backoff
+	}
+}
+
+// WithBearerToken authenticates every request with a bearer token.
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.authorize = func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+}
+
+// WithBasicAuth authenticates every request with HTTP basic auth.
+func WithBasicAuth(username, password string) Option {
+	return func(c *Client) {
+		c.authorize = func(req *http.Request) {
+			req.SetBasicAuth(username, password)
+		}
+	}
+}
+
+// New returns a client of the searchSvc instance at baseURL, such as
+// "http://localhost:8080".
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("searchsvc: invalid base URL %q", baseURL)
+	}
+	c := &Client{
+		baseURL:    u,
+		httpClient: http.DefaultClient,
+		timeout:    DefaultTimeout,
+		retries:    DefaultRetries,
+		backoff:    DefaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	// the timeout applies to a copy, leaving a shared client untouched
+	hc := *c.httpClient
+	hc.Timeout = c.timeout
+	c.httpClient = &hc
+	return c, nil
+}
+
+// Search returns the country with the given common, official, native or
+// translated name.
+func (c *Client) Search(ctx context.Context, name string) (*models.CountryMetadata, error) {
+	var country models.CountryMetadata
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/countries/search", url.Values{"name": {name}}, nil, &country); err != nil {
+		return nil, err
+	}
+	return &country, nil
+}
+
+// GetByCode returns the country with the given ISO 3166 alpha-2, alpha-3 or
+// numeric code, or IOC code.
+func (c *Client) GetByCode(ctx context.Context, code string) (*models.CountryMetadata, error) {
+	var country models.CountryMetadata
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/countries/by-code", url.Values{"code": {code}}, nil, &country); err != nil {
+		return nil, err
+	}
+	return &country, nil
+}
+
+// Suggest returns up to limit countries with a name starting with prefix,
+// the most populous first. A zero limit uses the default of the service.
+func (c *Client) Suggest(ctx context.Context, prefix string, limit int) ([]models.Suggestion, error) {
+	params := url.Values{"q": {prefix}}
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+	var suggestions []models.Suggestion
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/countries/suggest", params, nil, &suggestions); err != nil {
+		return nil, err
+	}
+	return suggestions, nil
+}
+
+// BatchResult is the outcome of an item of a batch: its country, or the
+// *Error it failed with.
+type BatchResult struct {
+	Item    models.BatchItem
+	Country *models.CountryMetadata
+	Err     error
+}
+
+type batchResponse struct {
+	Results []struct {
+		models.BatchItem
+		Country *models.CountryMetadata `json:"country"`
+		Error   *models.Problem         `json:"error"`
+	} `json:"results"`
+}
+
+// Batch looks up many countries in one call, by name or by code, and
+// returns their results in order. Failed items carry their error, unless
+// atomic is set: then the first failure fails the call.
+func (c *Client) Batch(ctx context.Context, items []models.BatchItem, atomic bool) ([]BatchResult, error) {
+	mode := "partial"
+	if atomic {
+		mode = "atomic"
+	}
+	body, err := json.Marshal(map[string]any{"items": items, "mode": mode})
+	if err != nil {
+		return nil, fmt.Errorf("searchsvc: encoding batch: %w", err)
+	}
+
+	var resp batchResponse
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/countries/batch", nil, body, &resp); err != nil {
+		return nil, err
+	}
+	results := make([]BatchResult, len(resp.Results))
+	for i, r := range resp.Results {
+		results[i] = BatchResult{Item: r.BatchItem, Country: r.Country}
+		if r.Error != nil {
+			results[i].Err = &Error{Problem: *r.Error}
+		}
+	}
+	return results, nil
+}
+
+// CacheStats reports the size and use of the cache of the service. Like the
+// other admin calls, it needs the admin token, passed WithBearerToken.
+func (c *Client) CacheStats(ctx context.Context) (*models.CacheStats, error) {
+	var stats models.CacheStats
+	if err := c.do(ctx, http.MethodGet, "/admin/cache", nil, nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// PurgeCache empties the cache of the service and returns how many entries
+// it held.
+func (c *Client) PurgeCache(ctx context.Context) (int, error) {
+	var resp struct {
+		Purged int `json:"purged"`
+	}
+	if err := c.do(ctx, http.MethodDelete, "/admin/cache", nil, nil, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Purged, nil
+}
+
+// WarmCache has the service load every country from upstream into its
+// cache and returns how many it cached.
+func (c *Client) WarmCache(ctx context.Context) (int, error) {
+	var resp struct {
+		Warmed int `json:"warmed"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/admin/cache/warm", nil, nil, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Warmed, nil
+}
+
+// do calls an endpoint of the service, retrying failures that may pass,
+// and decodes the JSON response into out.
+func (c *Client) do(ctx context.Context, method, path string, params url.Values, body []byte, out any) error {
+	u := c.baseURL.JoinPath(path)
+	u.RawQuery = params.Encode()
+
+	wait := c.backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := c.attempt(ctx, method, u.String(), body, out)
+		if err == nil || !retry || attempt == c.retries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// attempt sends a request once. It reports whether a failure is worth
+// retrying.
+func (c *Client) attempt(ctx context.Context, method, u string, body []byte, out any) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("searchsvc: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.authorize != nil {
+		c.authorize(req)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// a cancelled context fails every retry the same way
+		return ctx.Err() == nil, fmt.Errorf("searchsvc: %s %s: %w", method, req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		apiErr := decodeError(resp)
+		return retryable(resp.StatusCode), apiErr
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("searchsvc: decoding %s response: %w", req.URL.Path, err)
+	}
+	return false, nil
+}
+
+// retryable tells whether a call failing with status may pass later.
+func retryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
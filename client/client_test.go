@@ -0,0 +1,286 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Prasang-money/searchSvc/internal/servicetest"
+	"github.com/Prasang-money/searchSvc/models"
+	"github.com/Prasang-money/searchSvc/route"
+	"github.com/Prasang-money/searchSvc/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// setupServer serves the real router over HTTP, backed by svc.
+func setupServer(t *testing.T, svc service.ServiceInterface, wrap ...func(http.Handler) http.Handler) string {
+	gin.SetMode(gin.TestMode)
+	var h http.Handler = route.GetRoute(svc)
+	for _, w := range wrap {
+		h = w(h)
+	}
+	server := httptest.NewServer(h)
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func newClient(t *testing.T, baseURL string, opts ...Option) *Client {
+	c, err := New(baseURL, opts...)
+	require.NoError(t, err)
+	return c
+}
+
+func TestNew_InvalidBaseURL(t *testing.T) {
+	for _, baseURL := range []string{"", "localhost:8080", "ftp://example.com", "http://"} {
+		_, err := New(baseURL)
+		assert.Error(t, err, baseURL)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	mockService.On("SearchCountries", "Germany").Return(&servicetest.Germany, nil)
+	c := newClient(t, setupServer(t, mockService))
+
+	country, err := c.Search(context.Background(), "Germany")
+
+	require.NoError(t, err)
+	// v2 serves the full view
+	assert.Equal(t, servicetest.Germany, *country)
+}
+
+func TestSearch_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		err           error
+		wantErr       error
+		wantStatus    int
+		wantCode      string
+		wantRetryable bool
+	}{
+		{"not found", "Atlantis", &service.NotFoundError{Query: "Atlantis", Suggestions: []string{"Austria"}}, ErrNotFound, http.StatusNotFound, "not_found", false},
+		{"invalid", "Germany<1>", nil, ErrInvalidInput, http.StatusBadRequest, "invalid_input", false},
+		{"unavailable", "Germany", fmt.Errorf("%w: connection refused", service.ErrUpstreamUnavailable), ErrUpstreamUnavailable, http.StatusServiceUnavailable, "upstream_unavailable", true},
+		{"bad response", "Germany", fmt.Errorf("%w: status 500", service.ErrUpstreamBadResponse), ErrUpstreamBadResponse, http.StatusBadGateway, "upstream_bad_response", true},
+		{"timeout", "Germany", service.ErrTimeout, ErrTimeout, http.StatusGatewayTimeout, "upstream_timeout", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(servicetest.Mock)
+			mockService.On("SearchCountries", tt.query).Return(nil, tt.err)
+			c := newClient(t, setupServer(t, mockService), WithRetries(1, time.Millisecond))
+
+			country, err := c.Search(context.Background(), tt.query)
+
+			assert.Nil(t, country)
+			assert.ErrorIs(t, err, tt.wantErr)
+			var apiErr *Error
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tt.wantStatus, apiErr.Status)
+			assert.Equal(t, tt.wantCode, apiErr.Code)
+			assert.NotEmpty(t, apiErr.RequestID)
+			switch {
+			case tt.err == nil:
+				assert.Equal(t, "name", apiErr.InvalidParams[0].Name)
+				mockService.AssertNotCalled(t, "SearchCountries", mock.Anything)
+			case tt.wantRetryable:
+				mockService.AssertNumberOfCalls(t, "SearchCountries", 2)
+			default:
+				assert.Equal(t, []string{"Austria"}, apiErr.Suggestions)
+				mockService.AssertNumberOfCalls(t, "SearchCountries", 1)
+			}
+		})
+	}
+}
+
+func TestSearch_RetriesUntilSuccess(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	mockService.On("SearchCountries", "Germany").Return(nil, service.ErrUpstreamUnavailable).Twice()
+	mockService.On("SearchCountries", "Germany").Return(&servicetest.Germany, nil).Once()
+	c := newClient(t, setupServer(t, mockService), WithRetries(2, time.Millisecond))
+
+	country, err := c.Search(context.Background(), "Germany")
+
+	require.NoError(t, err)
+	assert.Equal(t, "Germany", country.Name)
+	mockService.AssertNumberOfCalls(t, "SearchCountries", 3)
+}
+
+func TestSearch_ContextCancelledDuringBackoff(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	mockService.On("SearchCountries", "Germany").Return(nil, service.ErrUpstreamUnavailable)
+	c := newClient(t, setupServer(t, mockService), WithRetries(5, time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := c.Search(ctx, "Germany")
+
+	assert.ErrorIs(t, err, ErrUpstreamUnavailable)
+	mockService.AssertNumberOfCalls(t, "SearchCountries", 1)
+}
+
+func TestTimeout(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	mockService.On("SearchByCode", "DE").Return(&servicetest.Germany, nil).After(200 * time.Millisecond)
+	c := newClient(t, setupServer(t, mockService), WithTimeout(20*time.Millisecond), WithRetries(0, 0))
+
+	_, err := c.GetByCode(context.Background(), "DE")
+
+	var netErr net.Error
+	require.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout())
+}
+
+func TestAuth(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Option
+		want string
+	}{
+		{"bearer", WithBearerToken("s3cret"), "Bearer s3cret"},
+		{"basic", WithBasicAuth("ops", "pa55"), "Basic b3BzOnBhNTU="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(servicetest.Mock)
+			mockService.On("SearchByCode", "DE").Return(&servicetest.Germany, nil)
+			var got string
+			baseURL := setupServer(t, mockService, func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					got = r.Header.Get("Authorization")
+					next.ServeHTTP(w, r)
+				})
+			})
+			c := newClient(t, baseURL, tt.opt)
+
+			_, err := c.GetByCode(context.Background(), "DE")
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetByCode(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	mockService.On("SearchByCode", "DEU").Return(&servicetest.Germany, nil)
+	c := newClient(t, setupServer(t, mockService))
+
+	country, err := c.GetByCode(context.Background(), "DEU")
+
+	require.NoError(t, err)
+	assert.Equal(t, servicetest.Germany, *country)
+}
+
+func TestBatch(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	items := []models.BatchItem{{Name: "Germany"}, {Code: "XX"}}
+	mockService.On("BatchLookup", items, false).Return([]service.BatchResult{
+		{Item: items[0], Country: &servicetest.Germany},
+		{Item: items[1], Err: fmt.Errorf("%w: XX", service.ErrNotFound)},
+	}, nil)
+	c := newClient(t, setupServer(t, mockService))
+
+	results, err := c.Batch(context.Background(), items, false)
+
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, items[0], results[0].Item)
+	assert.Equal(t, servicetest.Germany, *results[0].Country)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, items[1], results[1].Item)
+	assert.Nil(t, results[1].Country)
+	assert.ErrorIs(t, results[1].Err, ErrNotFound)
+}
+
+func TestBatch_Atomic(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	items := []models.BatchItem{{Name: "Germany"}, {Code: "1"}}
+	batchErr := &service.BatchError{Index: 1, Err: &service.ValidationError{Field: "code", Reason: "must be 2 or 3 letters or 3 digits"}}
+	mockService.On("BatchLookup", items, true).Return(nil, batchErr)
+	c := newClient(t, setupServer(t, mockService))
+
+	results, err := c.Batch(context.Background(), items, true)
+
+	assert.Nil(t, results)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "items[1].code", apiErr.InvalidParams[0].Name)
+}
+
+func TestSuggest(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	suggestions := []models.Suggestion{
+		{Name: "Germany", MatchedName: "Germany", Population: 83240525},
+		{Name: "Georgia", MatchedName: "Georgia", Population: 3714000},
+	}
+	mockService.On("Suggest", "Ge", 2).Return(suggestions, nil)
+	mockService.On("Suggest", "Ge", 10).Return(suggestions, nil)
+	c := newClient(t, setupServer(t, mockService))
+
+	got, err := c.Suggest(context.Background(), "Ge", 2)
+	require.NoError(t, err)
+	assert.Equal(t, suggestions, got)
+
+	// the service picks the limit
+	_, err = c.Suggest(context.Background(), "Ge", 0)
+	require.NoError(t, err)
+	mockService.AssertCalled(t, "Suggest", "Ge", 10)
+}
+
+func TestError_WithoutProblemDocument(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "<html>bad gateway</html>", http.StatusBadGateway)
+	}))
+	defer server.Close()
+	c := newClient(t, server.URL, WithRetries(0, 0))
+
+	_, err := c.Search(context.Background(), "Germany")
+
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadGateway, apiErr.Status)
+	assert.Equal(t, "Bad Gateway", apiErr.Title)
+	assert.False(t, errors.Is(err, ErrUpstreamBadResponse))
+	assert.Equal(t, "searchsvc: 502 Bad Gateway", err.Error())
+}
+
+func TestCacheAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(servicetest.Mock)
+	stats := models.CacheStats{Size: 6, Capacity: 2000, TTLSeconds: 21600, Hits: 3, Misses: 1}
+	mockService.On("CacheStats").Return(stats)
+	mockService.On("PurgeCache").Return(6)
+	mockService.On("WarmCache").Return(250, nil)
+	server := httptest.NewServer(route.GetRoute(mockService, route.WithAdminToken("s3cret")))
+	t.Cleanup(server.Close)
+	c := newClient(t, server.URL, WithBearerToken("s3cret"))
+	ctx := context.Background()
+
+	got, err := c.CacheStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, stats, *got)
+
+	purged, err := c.PurgeCache(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 6, purged)
+
+	warmed, err := c.WarmCache(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 250, warmed)
+
+	_, err = newClient(t, server.URL, WithBearerToken("wrong")).CacheStats(ctx)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Prasang-money/searchSvc/models"
+)
+
+// Errors matched by the errors the service answers with, under errors.Is.
+var (
+	ErrNotFound            = errors.New("country not found")
+	ErrInvalidInput        = errors.New("invalid input")
+	ErrUpstreamUnavailable = errors.New("upstream unavailable")
+	ErrUpstreamBadResponse = errors.New("bad response from upstream")
+	ErrTimeout             = errors.New("upstream request timed out")
+	ErrUnauthorized        = errors.New("unauthorized")
+)
+
+// codeErrors maps the error codes of problem documents to their errors.
+var codeErrors = map[string]error{
+	models.CodeNotFound:            ErrNotFound,
+	models.CodeInvalidInput:        ErrInvalidInput,
+	models.CodeUpstreamUnavailable: ErrUpstreamUnavailable,
+	models.CodeUpstreamBadResponse: ErrUpstreamBadResponse,
+	models.CodeTimeout:             ErrTimeout,
+	codeUnauthorized:               ErrUnauthorized,
+}
+
+// codeUnauthorized is the code of requests to the admin endpoints without
+// a valid token.
+const codeUnauthorized = "unauthorized"
+
+// Error is an error answered by the service, decoded from its problem
+// document. Responses without one, such as those of a proxy, are described
+// by their status only.
+type Error struct {
+	models.Problem
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("searchsvc: %d %s", e.Status, e.Title)
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	return msg
+}
+
+// Is matches the error of the code of the problem.
+func (e *Error) Is(target error) bool {
+	err, ok := codeErrors[e.Code]
+	return ok && err == target
+}
+
+// decodeError reads the problem document of a failed response.
+func decodeError(resp *http.Response) *Error {
+	apiErr := &Error{}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if err == nil {
+		// anything but a problem document leaves the fields empty
+		_ = json.Unmarshal(data, &apiErr.Problem)
+	}
+	if apiErr.Status == 0 {
+		apiErr.Status = resp.StatusCode
+	}
+	if apiErr.Title == "" {
+		apiErr.Title = http.StatusText(resp.StatusCode)
+	}
+	return apiErr
+}
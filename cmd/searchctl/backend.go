@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/Prasang-money/searchSvc/cache"
+	"github.com/Prasang-money/searchSvc/client"
+	"github.com/Prasang-money/searchSvc/models"
+	"github.com/Prasang-money/searchSvc/service"
+)
+
+// backend looks countries up, through a server or upstream. *client.Client
+// is one.
+type backend interface {
+	Search(ctx context.Context, name string) (*models.CountryMetadata, error)
+	GetByCode(ctx context.Context, code string) (*models.CountryMetadata, error)
+	Suggest(ctx context.Context, prefix string, limit int) ([]models.Suggestion, error)
+	Batch(ctx context.Context, items []models.BatchItem, atomic bool) ([]client.BatchResult, error)
+}
+
+// backend returns the client of the server, or with -direct a service
+// querying upstream.
+func (c *command) backend() (backend, error) {
+	if c.cfg.direct {
+		return direct{service.NewService(cache.NewCache(directCacheCapacity))}, nil
+	}
+	return c.client()
+}
+
+// direct looks countries up upstream through the service package, as a
+// server would. Its lookups cannot be cancelled.
+type direct struct {
+	service *service.Service
+}
+
+func (d direct) Search(_ context.Context, name string) (*models.CountryMetadata, error) {
+	return d.service.SearchCountries(name)
+}
+
+func (d direct) GetByCode(_ context.Context, code string) (*models.CountryMetadata, error) {
+	return d.service.SearchByCode(code)
+}
+
+func (d direct) Suggest(_ context.Context, prefix string, limit int) ([]models.Suggestion, error) {
+	return d.service.Suggest(prefix, limit)
+}
+
+func (d direct) Batch(_ context.Context, items []models.BatchItem, atomic bool) ([]client.BatchResult, error) {
+	results, err := d.service.BatchLookup(items, atomic)
+	if err != nil {
+		return nil, err
+	}
+	converted := make([]client.BatchResult, len(results))
+	for i, r := range results {
+		converted[i] = client.BatchResult{Item: r.Item, Country: r.Country, Err: r.Err}
+	}
+	return converted, nil
+}
+
+// codePattern matches ISO 3166 alpha-2, alpha-3 and numeric codes and IOC
+// codes, as written in capitals.
+var codePattern = regexp.MustCompile(`^([A-Z]{2,3}|[0-9]{3})$`)
+
+// readBatch reads the queries of a batch, one per line.
+func readBatch(r io.Reader) ([]models.BatchItem, error) {
+	var items []models.BatchItem
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if codePattern.MatchString(line) {
+			items = append(items, models.BatchItem{Code: line})
+		} else {
+			items = append(items, models.BatchItem{Name: line})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading queries: %w", err)
+	}
+	return items, nil
+}
+
+// lookupBatch looks items up in batches the service accepts. An atomic
+// lookup fails as a whole, but batches before the failing one have
+// already been looked up.
+func lookupBatch(ctx context.Context, b backend, items []models.BatchItem, atomic bool) ([]client.BatchResult, error) {
+	results := make([]client.BatchResult, 0, len(items))
+	for start := 0; start < len(items); start += service.MaxBatchSize {
+		end := min(start+service.MaxBatchSize, len(items))
+		chunk, err := b.Batch(ctx, items[start:end], atomic)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, chunk...)
+	}
+	return results, nil
+}
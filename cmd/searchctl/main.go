@@ -0,0 +1,282 @@
+// Command searchctl looks countries up from the command line, through a
+// searchSvc instance or straight from the upstream REST Countries API, and
+// administers the cache of an instance.
+//
+// Usage:
+//
+//	searchctl [flags] search <name>...
+//	searchctl [flags] code <code>...
+//	searchctl [flags] suggest [-limit n] <prefix>
+//	searchctl [flags] batch [-atomic] < queries
+//	searchctl [flags] cache stats|purge|warm
+//
+// Batch reads one query per line from stdin. Blank lines and lines starting
+// with # are skipped; queries that look like a code, such as DE, DEU or 276,
+// are looked up by code and the others by name.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/Prasang-money/searchSvc/client"
+	"github.com/Prasang-money/searchSvc/models"
+)
+
+// Exit codes of the command.
+const (
+	exitOK = iota
+	exitFailed
+	exitUsage
+)
+
+// defaultServer is the instance searched when neither -server nor
+// $SEARCHSVC_URL name one.
+const defaultServer = "http://localhost:8080"
+
+// directCacheCapacity sizes the cache of -direct lookups, which only live
+// as long as the command.
+const directCacheCapacity = 1000
+
+const usage = `Usage: searchctl [flags] <command> [args]
+
+Commands:
+  search <name>...           look countries up by name
+  code <code>...             look countries up by ISO 3166 or IOC code
+  suggest [-limit n] <prefix>
+                             suggest countries by name prefix
+  batch [-atomic]            look up the names and codes read from stdin
+  cache stats|purge|warm     show, empty or fill the cache of the server
+
+Flags:
+`
+
+// errUsage fails a command called with wrong arguments.
+var errUsage = errors.New("usage")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// config holds the global flags.
+type config struct {
+	server  string
+	direct  bool
+	format  string
+	timeout time.Duration
+	retries int
+	token   string
+}
+
+// run runs the command of args and returns its exit code.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	var cfg config
+	fs := flag.NewFlagSet("searchctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&cfg.server, "server", envOr("SEARCHSVC_URL", defaultServer), "base URL of the searchSvc instance, or $SEARCHSVC_URL")
+	fs.BoolVar(&cfg.direct, "direct", false, "query the upstream API directly instead of a server")
+	fs.StringVar(&cfg.format, "o", formatTable, "output format: table, json or csv")
+	fs.DurationVar(&cfg.timeout, "timeout", client.DefaultTimeout, "timeout of each request to the server")
+	fs.IntVar(&cfg.retries, "retries", client.DefaultRetries, "retries of failed requests to the server")
+	fs.StringVar(&cfg.token, "token", os.Getenv("SEARCHSVC_TOKEN"), "admin token of the server, or $SEARCHSVC_TOKEN")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if !validFormat(cfg.format) {
+		fmt.Fprintf(stderr, "searchctl: unknown output format %q\n", cfg.format)
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	cmd := &command{cfg: cfg, stdin: stdin, out: &printer{w: stdout, format: cfg.format}, stderr: stderr}
+	err := cmd.run(ctx, fs.Arg(0), fs.Args()[1:])
+	switch {
+	case errors.Is(err, errUsage):
+		fs.Usage()
+		return exitUsage
+	case err != nil:
+		fmt.Fprintf(stderr, "searchctl: %v\n", err)
+		return exitFailed
+	}
+	return exitOK
+}
+
+// command runs a command with the global flags.
+type command struct {
+	cfg    config
+	stdin  io.Reader
+	out    *printer
+	stderr io.Writer
+}
+
+func (c *command) run(ctx context.Context, name string, args []string) error {
+	if name == "cache" {
+		return c.cache(ctx, args)
+	}
+
+	b, err := c.backend()
+	if err != nil {
+		return err
+	}
+	switch name {
+	case "search":
+		return c.lookup(ctx, args, b.Search)
+	case "code":
+		return c.lookup(ctx, args, b.GetByCode)
+	case "suggest":
+		return c.suggest(ctx, b, args)
+	case "batch":
+		return c.batch(ctx, b, args)
+	}
+	fmt.Fprintf(c.stderr, "searchctl: unknown command %q\n", name)
+	return errUsage
+}
+
+// client returns a client of the server.
+func (c *command) client() (*client.Client, error) {
+	opts := []client.Option{
+		client.WithTimeout(c.cfg.timeout),
+		client.WithRetries(c.cfg.retries, client.DefaultBackoff),
+	}
+	if c.cfg.token != "" {
+		opts = append(opts, client.WithBearerToken(c.cfg.token))
+	}
+	return client.New(c.cfg.server, opts...)
+}
+
+// lookup looks each arg up and prints the countries found. Failed lookups
+// are reported on stderr and fail the command once all are done.
+func (c *command) lookup(ctx context.Context, args []string, find func(context.Context, string) (*models.CountryMetadata, error)) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	countries := make([]models.CountryMetadata, 0, len(args))
+	failed := 0
+	for _, arg := range args {
+		country, err := find(ctx, arg)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "searchctl: %s: %v\n", arg, err)
+			failed++
+			continue
+		}
+		countries = append(countries, *country)
+	}
+	if err := c.out.countries(countries); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d lookups failed", failed, len(args))
+	}
+	return nil
+}
+
+func (c *command) suggest(ctx context.Context, b backend, args []string) error {
+	fs := flag.NewFlagSet("suggest", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	limit := fs.Int("limit", 0, "most suggestions to return, 0 for the default of the service")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return errUsage
+	}
+
+	suggestions, err := b.Suggest(ctx, fs.Arg(0), *limit)
+	if err != nil {
+		return err
+	}
+	return c.out.suggestions(suggestions)
+}
+
+func (c *command) batch(ctx context.Context, b backend, args []string) error {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	atomic := fs.Bool("atomic", false, "fail without results if any query fails")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+		return errUsage
+	}
+
+	items, err := readBatch(c.stdin)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return errors.New("no queries on stdin")
+	}
+	results, err := lookupBatch(ctx, b, items, *atomic)
+	if err != nil {
+		return err
+	}
+	if err := c.out.batch(results); err != nil {
+		return err
+	}
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d lookups failed", failed, len(results))
+	}
+	return nil
+}
+
+func (c *command) cache(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	if c.cfg.direct {
+		return errors.New("cache commands administer a server and cannot run with -direct")
+	}
+	cl, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "stats":
+		stats, err := cl.CacheStats(ctx)
+		if err != nil {
+			return err
+		}
+		return c.out.cacheStats(*stats)
+	case "purge":
+		purged, err := cl.PurgeCache(ctx)
+		if err != nil {
+			return err
+		}
+		return c.out.count("purged", purged)
+	case "warm":
+		warmed, err := cl.WarmCache(ctx)
+		if err != nil {
+			return err
+		}
+		return c.out.count("warmed", warmed)
+	}
+	return errUsage
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Prasang-money/searchSvc/internal/servicetest"
+	"github.com/Prasang-money/searchSvc/models"
+	"github.com/Prasang-money/searchSvc/route"
+	"github.com/Prasang-money/searchSvc/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newService returns a mock service knowing Germany only.
+func newService() *servicetest.Mock {
+	germany := servicetest.Germany
+	notFound := &service.NotFoundError{Query: "Atlantis"}
+	m := new(servicetest.Mock)
+	m.On("SearchCountries", "Germany").Return(&germany, nil)
+	m.On("SearchCountries", "Atlantis").Return(nil, notFound)
+	m.On("SearchByCode", "DE").Return(&germany, nil)
+	m.On("BatchLookup", []models.BatchItem{{Name: "Germany"}, {Code: "DE"}, {Name: "Atlantis"}}, false).Return([]service.BatchResult{
+		{Item: models.BatchItem{Name: "Germany"}, Country: &germany},
+		{Item: models.BatchItem{Code: "DE"}, Country: &germany},
+		{Item: models.BatchItem{Name: "Atlantis"}, Err: notFound},
+	}, nil)
+	m.On("BatchLookup", []models.BatchItem{{Name: "Germany"}, {Name: "Atlantis"}}, true).Return(nil, &service.BatchError{Index: 1, Err: notFound})
+	m.On("Suggest", "Ger", 3).Return([]models.Suggestion{{Name: "Germany", MatchedName: "Germany", Population: germany.Population}}, nil)
+	m.On("CacheStats").Return(models.CacheStats{Size: 6, Capacity: 2000, TTLSeconds: 21600, Hits: 3, Misses: 1})
+	m.On("PurgeCache").Return(6)
+	m.On("WarmCache").Return(250, nil)
+	return m
+}
+
+const adminToken = "s3cret"
+
+// searchctl runs the command against a server backed by svc and returns
+// its exit code and output.
+func searchctl(t *testing.T, svc service.ServiceInterface, stdin string, args ...string) (int, string, string) {
+	gin.SetMode(gin.TestMode)
+	server := httptest.NewServer(route.GetRoute(svc, route.WithAdminToken(adminToken)))
+	t.Cleanup(server.Close)
+
+	var stdout, stderr bytes.Buffer
+	args = append([]string{"-server", server.URL, "-retries", "0"}, args...)
+	code := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestSearch(t *testing.T) {
+	code, stdout, stderr := searchctl(t, newService(), "", "search", "Germany")
+
+	assert.Equal(t, exitOK, code)
+	assert.Empty(t, stderr)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, []string{"name", "cca2", "cca3", "capital", "region", "population", "currencies"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"Germany", "DE", "DEU", "Berlin", "Europe", "83240525", "EUR"}, strings.Fields(lines[1]))
+}
+
+func TestSearch_PartlyFailed(t *testing.T) {
+	code, stdout, stderr := searchctl(t, newService(), "", "-o", "json", "search", "Germany", "Atlantis")
+
+	assert.Equal(t, exitFailed, code)
+	var countries []models.CountryMetadata
+	require.NoError(t, json.Unmarshal([]byte(stdout), &countries))
+	assert.Equal(t, []models.CountryMetadata{servicetest.Germany}, countries)
+	assert.Contains(t, stderr, "Atlantis")
+	assert.Contains(t, stderr, "1 of 2 lookups failed")
+}
+
+func TestCode_CSV(t *testing.T) {
+	code, stdout, _ := searchctl(t, newService(), "", "-o", "csv", "code", "DE")
+
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "name,cca2,cca3,capital,region,population,currencies\nGermany,DE,DEU,Berlin,Europe,83240525,EUR\n", stdout)
+}
+
+func TestSuggest(t *testing.T) {
+	code, stdout, _ := searchctl(t, newService(), "", "-o", "csv", "suggest", "-limit", "3", "Ger")
+
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "name,matchedName,population\nGermany,Germany,83240525\n", stdout)
+}
+
+func TestBatch(t *testing.T) {
+	stdin := "# countries\nGermany\n\nDE\nAtlantis\n"
+
+	code, stdout, stderr := searchctl(t, newService(), stdin, "-o", "csv", "batch")
+
+	assert.Equal(t, exitFailed, code)
+	assert.Equal(t, "query,name,cca2,cca3,capital,region,population,currencies,error\n"+
+		"Germany,Germany,DE,DEU,Berlin,Europe,83240525,EUR,\n"+
+		"DE,Germany,DE,DEU,Berlin,Europe,83240525,EUR,\n"+
+		"Atlantis,,,,,,,,searchsvc: 404 Not Found: country not found: Atlantis\n", stdout)
+	assert.Contains(t, stderr, "1 of 3 lookups failed")
+}
+
+func TestBatch_Atomic(t *testing.T) {
+	code, stdout, stderr := searchctl(t, newService(), "Germany\nAtlantis\n", "batch", "-atomic")
+
+	assert.Equal(t, exitFailed, code)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "Atlantis")
+}
+
+func TestReadBatch(t *testing.T) {
+	items, err := readBatch(strings.NewReader("Germany\n  DE  \nDEU\n276\nde\n# USA\nUnited States\n"))
+
+	require.NoError(t, err)
+	assert.Equal(t, []models.BatchItem{
+		{Name: "Germany"},
+		{Code: "DE"},
+		{Code: "DEU"},
+		{Code: "276"},
+		{Name: "de"},
+		{Name: "United States"},
+	}, items)
+}
+
+func TestCache(t *testing.T) {
+	svc := newService()
+
+	code, stdout, _ := searchctl(t, svc, "", "-token", adminToken, "-o", "json", "cache", "stats")
+	assert.Equal(t, exitOK, code)
+	assert.JSONEq(t, `{"size":6,"capacity":2000,"ttlSeconds":21600,"hits":3,"misses":1,"evictions":0,"expirations":0}`, stdout)
+
+	code, stdout, _ = searchctl(t, svc, "", "-token", adminToken, "cache", "purge")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "purged\n6\n", stdout)
+	svc.AssertNumberOfCalls(t, "PurgeCache", 1)
+
+	code, stdout, _ = searchctl(t, svc, "", "-token", adminToken, "-o", "json", "cache", "warm")
+	assert.Equal(t, exitOK, code)
+	assert.JSONEq(t, `{"warmed":250}`, stdout)
+}
+
+func TestCache_Unauthorized(t *testing.T) {
+	code, _, stderr := searchctl(t, newService(), "", "-token", "wrong", "cache", "purge")
+
+	assert.Equal(t, exitFailed, code)
+	assert.Contains(t, stderr, "401")
+}
+
+func TestCache_Direct(t *testing.T) {
+	code, _, stderr := searchctl(t, newService(), "", "-direct", "cache", "stats")
+
+	assert.Equal(t, exitFailed, code)
+	assert.Contains(t, stderr, "-direct")
+}
+
+func TestUsage(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"no command", nil},
+		{"unknown command", []string{"find", "Germany"}},
+		{"unknown format", []string{"-o", "xml", "search", "Germany"}},
+		{"search without names", []string{"search"}},
+		{"suggest without prefix", []string{"suggest"}},
+		{"unknown cache action", []string{"cache", "clear"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stdout, _ := searchctl(t, newService(), "", tt.args...)
+
+			assert.Equal(t, exitUsage, code)
+			assert.Empty(t, stdout)
+		})
+	}
+}
+
+// failingWriter fails every write, like a closed pipe.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func TestOutput_WriteFails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := httptest.NewServer(route.GetRoute(newService()))
+	t.Cleanup(server.Close)
+
+	for _, format := range []string{formatTable, formatJSON, formatCSV} {
+		t.Run(format, func(t *testing.T) {
+			var stderr bytes.Buffer
+			code := run(context.Background(), []string{"-server", server.URL, "-o", format, "code", "DE"}, strings.NewReader(""), failingWriter{}, &stderr)
+
+			assert.Equal(t, exitFailed, code)
+			assert.Contains(t, stderr.String(), "broken pipe")
+		})
+	}
+}
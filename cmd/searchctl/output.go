@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Prasang-money/searchSvc/client"
+	"github.com/Prasang-money/searchSvc/models"
+)
+
+// Output formats.
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+func validFormat(format string) bool {
+	return format == formatTable || format == formatJSON || format == formatCSV
+}
+
+// printer writes results in the output format. JSON output is the value
+// itself; table and CSV output are its rows.
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+// print writes v as JSON, or header and rows as a table or CSV.
+func (p *printer) print(v any, header []string, rows [][]string) error {
+	switch p.format {
+	case formatJSON:
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case formatCSV:
+		w := csv.NewWriter(p.w)
+		if err := w.Write(header); err != nil {
+			return err
+		}
+		return w.WriteAll(rows)
+	}
+	w := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+var countryHeader = []string{"name", "cca2", "cca3", "capital", "region", "population", "currencies"}
+
+func countryRow(country *models.CountryMetadata) []string {
+	if country == nil {
+		return make([]string, len(countryHeader))
+	}
+	codes := make([]string, len(country.Currencies))
+	for i, c := range country.Currencies {
+		codes[i] = c.Code
+	}
+	return []string{
+		country.Name,
+		country.CCA2,
+		country.CCA3,
+		country.Capital,
+		country.Region,
+		strconv.Itoa(country.Population),
+		strings.Join(codes, ","),
+	}
+}
+
+func (p *printer) countries(countries []models.CountryMetadata) error {
+	rows := make([][]string, len(countries))
+	for i := range countries {
+		rows[i] = countryRow(&countries[i])
+	}
+	return p.print(countries, countryHeader, rows)
+}
+
+func (p *printer) suggestions(suggestions []models.Suggestion) error {
+	rows := make([][]string, len(suggestions))
+	for i, s := range suggestions {
+		rows[i] = []string{s.Name, s.MatchedName, strconv.Itoa(s.Population)}
+	}
+	return p.print(suggestions, []string{"name", "matchedName", "population"}, rows)
+}
+
+// batchResult is the JSON output of an item of a batch.
+type batchResult struct {
+	models.BatchItem
+	Country *models.CountryMetadata `json:"country,omitempty"`
+	Error   string                  `json:"error,omitempty"`
+}
+
+func (p *printer) batch(results []client.BatchResult) error {
+	out := make([]batchResult, len(results))
+	rows := make([][]string, len(results))
+	for i, r := range results {
+		out[i] = batchResult{BatchItem: r.Item, Country: r.Country}
+		if r.Err != nil {
+			out[i].Error = r.Err.Error()
+		}
+		rows[i] = append([]string{r.Item.Name + r.Item.Code}, countryRow(r.Country)...)
+		rows[i] = append(rows[i], out[i].Error)
+	}
+	header := append([]string{"query"}, countryHeader...)
+	return p.print(out, append(header, "error"), rows)
+}
+
+func (p *printer) cacheStats(stats models.CacheStats) error {
+	row := []string{
+		strconv.Itoa(stats.Size),
+		strconv.Itoa(stats.Capacity),
+		strconv.FormatInt(stats.TTLSeconds, 10),
+		strconv.FormatUint(stats.Hits, 10),
+		strconv.FormatUint(stats.Misses, 10),
+		strconv.FormatUint(stats.Evictions, 10),
+		strconv.FormatUint(stats.Expirations, 10),
+	}
+	header := []string{"size", "capacity", "ttlSeconds", "hits", "misses", "evictions", "expirations"}
+	return p.print(stats, header, [][]string{row})
+}
+
+// count writes the outcome of an admin action, such as the entries purged.
+func (p *printer) count(name string, n int) error {
+	return p.print(map[string]int{name: n}, []string{name}, [][]string{{strconv.Itoa(n)}})
+}
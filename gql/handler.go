@@ -0,0 +1,127 @@
+package gql
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Prasang-money/searchSvc/service"
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// maxRequestBody bounds the size of a POST request.
+const maxRequestBody = 1 << 20
+
+// Handler serves GraphQL queries over HTTP.
+type Handler struct {
+	schema  graphql.Schema
+	service service.ServiceInterface
+}
+
+// NewHandler returns a handler resolving queries with svc. It panics if the
+// schema is invalid, which is a programming error.
+func NewHandler(svc service.ServiceInterface) *Handler {
+	schema, err := newSchema(svc)
+	if err != nil {
+		panic("gql: invalid schema: " + err.Error())
+	}
+	return &Handler{
+		schema:  schema,
+		service: svc,
+	}
+}
+
+// request is a GraphQL request, sent as the JSON body of a POST or as the
+// query parameters of a GET.
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Query answers GET and POST requests to the GraphQL endpoint. Requests that
+// cannot be executed, because they are malformed, invalid or exceed the
+// limits, are answered with 400 and only errors; executed ones with 200,
+// with field errors next to the data.
+func (handler *Handler) Query() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		req, err := parseRequest(c)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+
+		doc, err := parseQuery(req.Query)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		if result := graphql.ValidateDocument(&handler.schema, doc, nil); !result.IsValid {
+			writeErrors(c, http.StatusBadRequest, result.Errors)
+			return
+		}
+		if err := checkLimits(doc, req.Variables); err != nil {
+			writeError(c, err)
+			return
+		}
+
+		result := graphql.Execute(graphql.ExecuteParams{
+			Schema:        handler.schema,
+			AST:           doc,
+			OperationName: req.OperationName,
+			Args:          req.Variables,
+			Context:       withLoader(c.Request.Context(), newLoader(handler.service)),
+		})
+		c.JSON(http.StatusOK, result)
+
+	}
+
+}
+
+func parseRequest(c *gin.Context) (request, error) {
+	var req request
+	if c.Request.Method == http.MethodPost {
+		body := http.MaxBytesReader(c.Writer, c.Request.Body, maxRequestBody)
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			return req, errors.New("request body must be a JSON object with a query")
+		}
+	} else {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if raw := c.Query("variables"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &req.Variables); err != nil {
+				return req, errors.New("variables must be a JSON object")
+			}
+		}
+	}
+	if req.Query == "" {
+		return req, errors.New("query is required")
+	}
+	return req, nil
+}
+
+func parseQuery(query string) (*ast.Document, error) {
+	return parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(query), Name: "GraphQL request"}),
+	})
+}
+
+// writeError rejects a request that cannot be executed, keeping the
+// extensions of err.
+func writeError(c *gin.Context, err error) {
+	formatted := gqlerrors.FormatError(err)
+	if ext, ok := err.(gqlerrors.ExtendedError); ok {
+		formatted.Extensions = ext.Extensions()
+	}
+	writeErrors(c, http.StatusBadRequest, []gqlerrors.FormattedError{formatted})
+}
+
+func writeErrors(c *gin.Context, status int, errs []gqlerrors.FormattedError) {
+	c.AbortWithStatusJSON(status, &graphql.Result{Errors: errs})
+}
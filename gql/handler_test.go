@@ -0,0 +1,296 @@
+package gql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Prasang-money/searchSvc/internal/servicetest"
+	"github.com/Prasang-money/searchSvc/models"
+	"github.com/Prasang-money/searchSvc/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTestRouter(svc service.ServiceInterface) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := NewHandler(svc)
+	router.GET("/graphql", handler.Query())
+	router.POST("/graphql", handler.Query())
+	return router
+}
+
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Path       []any          `json:"path"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+func post(t *testing.T, router *gin.Engine, query string, variables map[string]any) (int, response) {
+	body, _ := json.Marshal(request{Query: query, Variables: variables})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	var resp response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding response %q: %v", w.Body.String(), err)
+	}
+	return w.Code, resp
+}
+
+func codes(codes ...string) []models.BatchItem {
+	items := make([]models.BatchItem, len(codes))
+	for i, code := range codes {
+		items[i] = models.BatchItem{Code: code}
+	}
+	return items
+}
+
+func TestCountry_BordersAreBatched(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	router := setupTestRouter(mockService)
+
+	mockService.On("SearchByCode", "DE").Return(&servicetest.Germany, nil)
+	mockService.On("BatchLookup", codes("AUT", "CHE", "FRA"), false).Return([]service.BatchResult{
+		{Country: &servicetest.Austria}, {Country: &servicetest.Switzerland}, {Country: &servicetest.France},
+	}, nil)
+
+	status, resp := post(t, router, `{
+		country(code: "DE") {
+			name(lang: "fr")
+			borders { name capital borders { cca3 } }
+		}
+	}`, nil)
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"country": {
+		"name": "Allemagne",
+		"borders": [
+			{"name": "Austria", "capital": "Vienna", "borders": [{"cca3": "DEU"}, {"cca3": "CHE"}]},
+			{"name": "Switzerland", "capital": "Bern", "borders": [{"cca3": "AUT"}, {"cca3": "DEU"}, {"cca3": "FRA"}]},
+			{"name": "France", "capital": "Paris", "borders": [{"cca3": "CHE"}, {"cca3": "DEU"}]}
+		]
+	}}`, string(resp.Data))
+
+	// The second level only needs countries that are already loaded
+	mockService.AssertNumberOfCalls(t, "BatchLookup", 1)
+	mockService.AssertExpectations(t)
+}
+
+func TestCountries(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	router := setupTestRouter(mockService)
+
+	landlocked := true
+	mockService.On("ListCountries", service.ListQuery{
+		Limit:  2,
+		Cursor: "o:0",
+		Filter: service.Filter{Region: "Europe", Landlocked: &landlocked},
+	}).Return(&service.Page{
+		Countries:  []models.SearchHit{{CountryMetadata: servicetest.Austria}, {CountryMetadata: servicetest.Switzerland}},
+		Total:      3,
+		NextCursor: "o:2",
+	}, nil)
+	// The borders of both nodes are fetched together, without the nodes
+	// themselves
+	mockService.On("BatchLookup", codes("DEU", "FRA"), false).Return([]service.BatchResult{
+		{Country: &servicetest.Germany}, {Country: &servicetest.France},
+	}, nil)
+
+	status, resp := post(t, router, `query($first: Int) {
+		countries(region: "Europe", landlocked: true, first: $first, after: "o:0") {
+			totalCount
+			nextCursor
+			nodes { name landlocked borders { name } }
+		}
+	}`, map[string]any{"first": 2})
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"countries": {
+		"totalCount": 3,
+		"nextCursor": "o:2",
+		"nodes": [
+			{"name": "Austria", "landlocked": true, "borders": [{"name": "Germany"}, {"name": "Switzerland"}]},
+			{"name": "Switzerland", "landlocked": true, "borders": [{"name": "Austria"}, {"name": "Germany"}, {"name": "France"}]}
+		]
+	}}`, string(resp.Data))
+
+	mockService.AssertNumberOfCalls(t, "BatchLookup", 1)
+	mockService.AssertExpectations(t)
+}
+
+func TestErrors(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	router := setupTestRouter(mockService)
+
+	mockService.On("SearchCountries", "Atlantis").Return(nil, &service.NotFoundError{Query: "Atlantis", Suggestions: []string{"Austria"}})
+
+	status, resp := post(t, router, `{ country(name: "Atlantis") { name } }`, nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"country": null}`, string(resp.Data))
+	if assert.Len(t, resp.Errors, 1) {
+		assert.Equal(t, []any{"country"}, resp.Errors[0].Path)
+		assert.Equal(t, "not_found", resp.Errors[0].Extensions["code"])
+		assert.Equal(t, []any{"Austria"}, resp.Errors[0].Extensions["suggestions"])
+	}
+
+	// Arguments are validated like the REST parameters
+	_, resp = post(t, router, `{ country(name: "Germany", code: "DE") { name } }`, nil)
+	if assert.Len(t, resp.Errors, 1) {
+		assert.Equal(t, "invalid_input", resp.Errors[0].Extensions["code"])
+		assert.Equal(t, "code", resp.Errors[0].Extensions["field"])
+	}
+	_, resp = post(t, router, `{ countries(first: 51) { totalCount } }`, nil)
+	if assert.Len(t, resp.Errors, 1) {
+		assert.Equal(t, "first", resp.Errors[0].Extensions["field"])
+	}
+
+	mockService.AssertExpectations(t)
+}
+
+func TestLimits(t *testing.T) {
+	router := setupTestRouter(new(servicetest.Mock))
+
+	nested := func(levels int) string {
+		return `{ country(code: "DE") { ` + strings.Repeat("borders { ", levels) + "name" +
+			strings.Repeat(" }", levels) + " } }"
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		code  string
+	}{
+		{"too deep", nested(MaxDepth - 1), CodeQueryTooDeep},
+		{"too deep through fragments", `{ country(code: "DE") { ...b } }
+			fragment b on Country { borders { ...c } }
+			fragment c on Country { borders { borders { borders { borders { borders { borders { borders { borders { name } } } } } } } } }`, CodeQueryTooDeep},
+		{"too complex", `{ countries(first: 50) { nodes { borders { borders { name } } } } }`, CodeQueryTooComplex},
+		{"too complex through variables", `query($n: Int) { countries(first: $n) { nodes { borders { borders { name } } } } }`, CodeQueryTooComplex},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := post(t, router, tt.query, map[string]any{"n": 50})
+			assert.Equal(t, http.StatusBadRequest, status)
+			assert.Equal(t, "null", string(resp.Data))
+			if assert.Len(t, resp.Errors, 1) {
+				assert.Equal(t, tt.code, resp.Errors[0].Extensions["code"])
+			}
+		})
+	}
+}
+
+func TestLimits_Allowed(t *testing.T) {
+	doc, err := parseQuery(`{ countries { nodes { name borders { name borders { name } } } } }`)
+	assert.NoError(t, err)
+	assert.NoError(t, checkLimits(doc, nil))
+
+	// The deepest allowed query passes the depth limit, but its borders
+	// would fan out too much
+	doc, err = parseQuery(`{ country(code: "DE") { ` + strings.Repeat("borders { ", MaxDepth-2) + "name" +
+		strings.Repeat(" }", MaxDepth-2) + " } }")
+	assert.NoError(t, err)
+	var limitErr *limitError
+	if assert.ErrorAs(t, checkLimits(doc, nil), &limitErr) {
+		assert.Equal(t, CodeQueryTooComplex, limitErr.code)
+	}
+}
+
+func TestQuery_BadRequests(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	router := setupTestRouter(mockService)
+
+	tests := []struct {
+		name string
+		req  *http.Request
+	}{
+		{"missing query", httptest.NewRequest(http.MethodGet, "/graphql", nil)},
+		{"malformed body", httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader("{"))},
+		{"syntax error", httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape("{ country("), nil)},
+		{"unknown field", httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape("{ capital }"), nil)},
+		{"malformed variables", httptest.NewRequest(http.MethodGet, "/graphql?query=%7Bcountries%7BtotalCount%7D%7D&variables=%5B", nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, tt.req)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), `"errors"`)
+		})
+	}
+
+	// Queries can be sent as GET parameters
+	mockService.On("SearchByCode", "FR").Return(&servicetest.France, nil)
+	w := httptest.NewRecorder()
+	query := url.Values{
+		"query":     {`query($code: String) { country(code: $code) { name } }`},
+		"variables": {`{"code": "FR"}`},
+	}
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/graphql?"+query.Encode(), nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data": {"country": {"name": "France"}}}`, w.Body.String())
+}
+
+func TestBorders_UpstreamFailure(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	router := setupTestRouter(mockService)
+
+	mockService.On("SearchByCode", "DE").Return(&servicetest.Germany, nil)
+	mockService.On("BatchLookup", codes("AUT", "CHE", "FRA"), false).Return(nil, fmt.Errorf("%w: down", service.ErrUpstreamUnavailable))
+
+	status, resp := post(t, router, `{ country(code: "DE") { name borders { name } } }`, nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"country": {"name": "Germany", "borders": null}}`, string(resp.Data))
+	if assert.Len(t, resp.Errors, 1) {
+		assert.Equal(t, "the country data source is unavailable", resp.Errors[0].Message)
+	}
+}
+
+func TestErrors_Redacted(t *testing.T) {
+	var logs bytes.Buffer
+	out := log.Writer()
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(out) })
+	mockService := new(servicetest.Mock)
+	router := setupTestRouter(mockService)
+
+	const upstreamURL = "https://restcountries.com/v3.1/alpha/DE"
+	mockService.On("SearchByCode", "DE").Return(nil, fmt.Errorf("%w: Get %q: dial tcp: connection refused", service.ErrUpstreamUnavailable, upstreamURL))
+	mockService.On("SearchByCode", "FR").Return(nil, fmt.Errorf("decoding %s: boom", upstreamURL))
+
+	tests := []struct {
+		code        string
+		wantCode    string
+		wantMessage string
+	}{
+		{"DE", "upstream_unavailable", "the country data source is unavailable"},
+		{"FR", "internal_error", "internal server error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			_, resp := post(t, router, `query($code: String) { country(code: $code) { name } }`, map[string]any{"code": tt.code})
+			if assert.Len(t, resp.Errors, 1) {
+				assert.Equal(t, tt.wantCode, resp.Errors[0].Extensions["code"])
+				assert.Equal(t, tt.wantMessage, resp.Errors[0].Message)
+			}
+		})
+	}
+	// The cause is logged instead
+	assert.Contains(t, logs.String(), upstreamURL)
+}
@@ -0,0 +1,154 @@
+package gql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Limits of a query, checked before it is executed.
+const (
+	// MaxDepth is the deepest nesting of fields a query may select. The
+	// fields of country are at depth 2.
+	MaxDepth = 10
+
+	// MaxComplexity bounds the estimated number of fields a query resolves.
+	MaxComplexity = 1000
+)
+
+// Error codes of rejected queries.
+const (
+	CodeQueryTooDeep    = "query_too_deep"
+	CodeQueryTooComplex = "query_too_complex"
+)
+
+// bordersEstimate is the assumed number of neighbours of a country when
+// estimating the complexity of a query. Few countries have more.
+const bordersEstimate = 8
+
+// limitError is a query rejected by the depth or complexity limits.
+type limitError struct {
+	code    string
+	message string
+}
+
+func (e *limitError) Error() string {
+	return e.message
+}
+
+func (e *limitError) Extensions() map[string]any {
+	return map[string]any{"code": e.code}
+}
+
+// checkLimits rejects operations of doc nesting fields deeper than MaxDepth
+// or estimated to cost more than MaxComplexity. doc must be valid, so that
+// fragments are known and not cyclic.
+func checkLimits(doc *ast.Document, variables map[string]any) error {
+	c := limits{
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+	}
+	for _, def := range doc.Definitions {
+		if frag, ok := def.(*ast.FragmentDefinition); ok {
+			c.fragments[frag.Name.Value] = frag
+		}
+	}
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if depth := c.depth(op.SelectionSet); depth > MaxDepth {
+			return &limitError{CodeQueryTooDeep, fmt.Sprintf("query has depth %d, the limit is %d", depth, MaxDepth)}
+		}
+		if cost := c.complexity(op.SelectionSet); cost > MaxComplexity {
+			return &limitError{CodeQueryTooComplex, fmt.Sprintf("query has complexity %d, the limit is %d", cost, MaxComplexity)}
+		}
+	}
+	return nil
+}
+
+type limits struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]any
+}
+
+// fields returns the fields of a selection set, with fragments expanded.
+// Introspection fields are left out: their depth is bounded by the schema.
+func (c limits) fields(set *ast.SelectionSet) []*ast.Field {
+	if set == nil {
+		return nil
+	}
+	var fields []*ast.Field
+	for _, sel := range set.Selections {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if !strings.HasPrefix(sel.Name.Value, "__") {
+				fields = append(fields, sel)
+			}
+		case *ast.InlineFragment:
+			fields = append(fields, c.fields(sel.SelectionSet)...)
+		case *ast.FragmentSpread:
+			if frag := c.fragments[sel.Name.Value]; frag != nil {
+				fields = append(fields, c.fields(frag.SelectionSet)...)
+			}
+		}
+	}
+	return fields
+}
+
+func (c limits) depth(set *ast.SelectionSet) int {
+	deepest := 0
+	for _, f := range c.fields(set) {
+		deepest = max(deepest, 1+c.depth(f.SelectionSet))
+	}
+	return deepest
+}
+
+// complexity counts one per field, multiplying the fields below a list by
+// its expected length.
+func (c limits) complexity(set *ast.SelectionSet) int {
+	total := 0
+	for _, f := range c.fields(set) {
+		total += 1 + c.multiplier(f)*c.complexity(f.SelectionSet)
+	}
+	return total
+}
+
+// multiplier is the expected number of objects a field resolves to.
+func (c limits) multiplier(f *ast.Field) int {
+	switch f.Name.Value {
+	case "countries":
+		// larger pages are rejected by the resolver
+		return min(c.intArg(f, "first", defaultFirst), maxFirst)
+	case "borders":
+		return bordersEstimate
+	}
+	return 1
+}
+
+func (c limits) intArg(f *ast.Field, name string, def int) int {
+	for _, arg := range f.Arguments {
+		if arg.Name.Value != name {
+			continue
+		}
+		value := arg.Value.GetValue()
+		if v, ok := arg.Value.(*ast.Variable); ok {
+			value = c.variables[v.Name.Value]
+		}
+		switch value := value.(type) {
+		case string:
+			// literals are kept as written
+			if i, err := strconv.Atoi(value); err == nil {
+				return i
+			}
+		case float64:
+			// variables are decoded from JSON
+			return int(value)
+		}
+		return def
+	}
+	return def
+}
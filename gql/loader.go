@@ -0,0 +1,122 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/Prasang-money/searchSvc/models"
+	"github.com/Prasang-money/searchSvc/service"
+)
+
+type loaderKey struct{}
+
+// loader batches the lookups by code of one request. Resolvers register the
+// codes they need and return a thunk; the executor calls the thunks of a
+// level only after resolving every field of that level, so the first thunk
+// fetches all the registered codes with a single batch lookup.
+type loader struct {
+	service service.ServiceInterface
+
+	mu      sync.Mutex
+	pending []string
+	queued  map[string]bool
+	loaded  map[string]loadResult
+}
+
+type loadResult struct {
+	country *models.CountryMetadata
+	err     error
+}
+
+func newLoader(svc service.ServiceInterface) *loader {
+	return &loader{
+		service: svc,
+		queued:  make(map[string]bool),
+		loaded:  make(map[string]loadResult),
+	}
+}
+
+func withLoader(ctx context.Context, l *loader) context.Context {
+	return context.WithValue(ctx, loaderKey{}, l)
+}
+
+func loaderFrom(ctx context.Context) *loader {
+	return ctx.Value(loaderKey{}).(*loader)
+}
+
+// prime records a country resolved by other means, so that it is not looked
+// up again when it appears as a border.
+func (l *loader) prime(country models.CountryMetadata) {
+	if country.CCA3 == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.loaded[country.CCA3]; !ok {
+		l.loaded[country.CCA3] = loadResult{country: &country}
+	}
+}
+
+// loadMany queues the codes and returns a thunk resolving to the countries
+// they identify. Codes that match no country are skipped.
+func (l *loader) loadMany(codes []string) func() (any, error) {
+	l.mu.Lock()
+	for _, code := range codes {
+		if _, ok := l.loaded[code]; !ok && !l.queued[code] {
+			l.queued[code] = true
+			l.pending = append(l.pending, code)
+		}
+	}
+	l.mu.Unlock()
+
+	return func() (any, error) {
+		l.flush()
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		countries := make([]models.CountryMetadata, 0, len(codes))
+		for _, code := range codes {
+			r := l.loaded[code]
+			if errors.Is(r.err, service.ErrNotFound) {
+				continue
+			}
+			if r.err != nil {
+				return nil, resolverError(r.err)
+			}
+			countries = append(countries, *r.country)
+		}
+		return countries, nil
+	}
+}
+
+// flush looks up every pending code, in batches of at most
+// service.MaxBatchSize.
+func (l *loader) flush() {
+	l.mu.Lock()
+	pending := l.pending
+	l.pending = nil
+	l.mu.Unlock()
+
+	for len(pending) > 0 {
+		chunk := pending[:min(len(pending), service.MaxBatchSize)]
+		pending = pending[len(chunk):]
+
+		items := make([]models.BatchItem, len(chunk))
+		for i, code := range chunk {
+			items[i] = models.BatchItem{Code: code}
+		}
+		results, err := l.service.BatchLookup(items, false)
+
+		l.mu.Lock()
+		for i, code := range chunk {
+			delete(l.queued, code)
+			if err != nil {
+				l.loaded[code] = loadResult{err: err}
+			} else {
+				l.loaded[code] = loadResult{country: results[i].Country, err: results[i].Err}
+			}
+		}
+		l.mu.Unlock()
+	}
+}
@@ -0,0 +1,315 @@
+// Package gql serves a GraphQL API over the country model, on top of the same
+// service layer as the REST API.
+package gql
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/Prasang-money/searchSvc/models"
+	"github.com/Prasang-money/searchSvc/service"
+	"github.com/graphql-go/graphql"
+)
+
+// Bounds of the first argument of the countries field, as for the limit of
+// the REST list endpoint.
+const (
+	defaultFirst = 10
+	maxFirst     = 50
+)
+
+var currencyType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Currency",
+	Description: "An ISO 4217 currency as used by a country.",
+	Fields: graphql.Fields{
+		"code":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"name":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"symbol": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+	},
+})
+
+var languageType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Language",
+	Description: "A language spoken in a country, keyed by its ISO 639-3 code.",
+	Fields: graphql.Fields{
+		"code": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"name": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+	},
+})
+
+var flagsType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Flags",
+	Description: "The URLs of a country's flag images.",
+	Fields: graphql.Fields{
+		"png": &graphql.Field{Type: graphql.String},
+		"svg": &graphql.Field{Type: graphql.String},
+		"alt": &graphql.Field{Type: graphql.String},
+	},
+})
+
+var translationType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Translation",
+	Description: "The common name of a country in a language.",
+	Fields: graphql.Fields{
+		"language": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Description: "ISO 639-3 language code."},
+		"name":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+	},
+})
+
+// translation is an entry of models.CountryMetadata.Translations.
+type translation struct {
+	Language string `json:"language"`
+	Name     string `json:"name"`
+}
+
+// resolver resolves the fields of the schema with the service.
+type resolver struct {
+	service service.ServiceInterface
+}
+
+// newSchema builds the GraphQL schema, resolved with svc.
+func newSchema(svc service.ServiceInterface) (graphql.Schema, error) {
+	r := resolver{service: svc}
+
+	countryType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Country",
+		Description: "A country, with every field of the full REST view.",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "The common name, in the given ISO 639-1 language or in English.",
+				Args: graphql.FieldConfigArgument{
+					"lang": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					lang, _ := p.Args["lang"].(string)
+					return countryOf(p).LocalizedName(lang), nil
+				},
+			},
+			"population": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"capital":    &graphql.Field{Type: graphql.String, Description: "The first capital."},
+			"currency":   &graphql.Field{Type: graphql.String, Description: "The symbol of the currency with the lowest code."},
+			"cca2":       &graphql.Field{Type: graphql.String},
+			"cca3":       &graphql.Field{Type: graphql.String},
+			"ccn3":       &graphql.Field{Type: graphql.String},
+			"cioc":       &graphql.Field{Type: graphql.String},
+			"capitals":   &graphql.Field{Type: stringList},
+			"currencies": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(currencyType)))},
+			"region":     &graphql.Field{Type: graphql.String},
+			"subregion":  &graphql.Field{Type: graphql.String},
+			"languages":  &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(languageType)))},
+			"latlng":     &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.Float)))},
+			"area":       &graphql.Field{Type: graphql.Float},
+			"landlocked": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"timezones":  &graphql.Field{Type: stringList},
+			"flags": &graphql.Field{
+				Type: flagsType,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					// a nil *models.Flags would not resolve to null
+					if flags := countryOf(p).Flags; flags != nil {
+						return *flags, nil
+					}
+					return nil, nil
+				},
+			},
+			"callingCodes": &graphql.Field{Type: stringList},
+			"tld":          &graphql.Field{Type: stringList},
+			"translations": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(translationType))),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					translations := make([]translation, 0, len(countryOf(p).Translations))
+					for lang, name := range countryOf(p).Translations {
+						translations = append(translations, translation{Language: lang, Name: name})
+					}
+					sort.Slice(translations, func(i, j int) bool {
+						return translations[i].Language < translations[j].Language
+					})
+					return translations, nil
+				},
+			},
+		},
+	})
+	// borders refers to the type it belongs to, so it is added afterwards
+	// It is nullable so that a failed lookup only nulls the borders.
+	countryType.AddFieldConfig("borders", &graphql.Field{
+		Type:        graphql.NewList(graphql.NewNonNull(countryType)),
+		Description: "The neighbouring countries, loaded in one batch per level of the query.",
+		Resolve:     r.borders,
+	})
+
+	connectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "CountryConnection",
+		Description: "A page of countries.",
+		Fields: graphql.Fields{
+			"nodes":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(countryType)))},
+			"totalCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"nextCursor": &graphql.Field{Type: graphql.String, Description: "Pass as after to get the next page."},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"country": &graphql.Field{
+				Type:        countryType,
+				Description: "Looks a country up by name or by code.",
+				Args: graphql.FieldConfigArgument{
+					"name": &graphql.ArgumentConfig{Type: graphql.String},
+					"code": &graphql.ArgumentConfig{Type: graphql.String, Description: "ISO 3166 or IOC code."},
+				},
+				Resolve: r.country,
+			},
+			"countries": &graphql.Field{
+				Type:        graphql.NewNonNull(connectionType),
+				Description: "Lists, searches and filters countries, a page at a time.",
+				Args: graphql.FieldConfigArgument{
+					"query":         &graphql.ArgumentConfig{Type: graphql.String},
+					"region":        &graphql.ArgumentConfig{Type: graphql.String},
+					"language":      &graphql.ArgumentConfig{Type: graphql.String},
+					"currency":      &graphql.ArgumentConfig{Type: graphql.String},
+					"minPopulation": &graphql.ArgumentConfig{Type: graphql.Int},
+					"maxPopulation": &graphql.ArgumentConfig{Type: graphql.Int},
+					"landlocked":    &graphql.ArgumentConfig{Type: graphql.Boolean},
+					"sort":          &graphql.ArgumentConfig{Type: graphql.String},
+					"first":         &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultFirst},
+					"after":         &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: r.countries,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+var stringList = graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))
+
+// countryOf returns the country a field is resolved on.
+func countryOf(p graphql.ResolveParams) models.CountryMetadata {
+	return p.Source.(models.CountryMetadata)
+}
+
+func (r resolver) country(p graphql.ResolveParams) (any, error) {
+	name, hasName := p.Args["name"].(string)
+	code, hasCode := p.Args["code"].(string)
+
+	var (
+		resp *models.CountryMetadata
+		err  error
+	)
+	switch {
+	case hasName && hasCode:
+		err = &service.ValidationError{Field: "code", Reason: "must not be set along with name"}
+	case hasCode:
+		if err = service.ValidateCode("code", code); err == nil {
+			resp, err = r.service.SearchByCode(code)
+		}
+	default:
+		if err = service.ValidateName("name", name); err == nil {
+			resp, err = r.service.SearchCountries(name)
+		}
+	}
+	if err != nil {
+		return nil, resolverError(err)
+	}
+	loaderFrom(p.Context).prime(*resp)
+	return *resp, nil
+}
+
+func (r resolver) countries(p graphql.ResolveParams) (any, error) {
+	q := service.ListQuery{
+		Query:  stringArg(p, "query"),
+		Sort:   stringArg(p, "sort"),
+		Cursor: stringArg(p, "after"),
+		Filter: service.Filter{
+			Region:   stringArg(p, "region"),
+			Language: stringArg(p, "language"),
+			Currency: stringArg(p, "currency"),
+		},
+	}
+	q.Limit, _ = p.Args["first"].(int)
+	if q.Limit < 1 || q.Limit > maxFirst {
+		return nil, resolverError(&service.ValidationError{Field: "first", Reason: fmt.Sprintf("must be an integer between 1 and %d", maxFirst)})
+	}
+	if v, ok := p.Args["minPopulation"].(int); ok {
+		q.Filter.MinPopulation = &v
+	}
+	if v, ok := p.Args["maxPopulation"].(int); ok {
+		q.Filter.MaxPopulation = &v
+	}
+	if v, ok := p.Args["landlocked"].(bool); ok {
+		q.Filter.Landlocked = &v
+	}
+
+	page, err := r.service.ListCountries(q)
+	if err != nil {
+		return nil, resolverError(err)
+	}
+	nodes := make([]models.CountryMetadata, len(page.Countries))
+	for i, hit := range page.Countries {
+		nodes[i] = hit.CountryMetadata
+		loaderFrom(p.Context).prime(hit.CountryMetadata)
+	}
+	return map[string]any{
+		"nodes":      nodes,
+		"totalCount": page.Total,
+		"nextCursor": page.NextCursor,
+	}, nil
+}
+
+// borders defers loading the neighbours of a country, so that the loader
+// fetches the borders of every country at the same level at once.
+func (r resolver) borders(p graphql.ResolveParams) (any, error) {
+	codes := countryOf(p).Borders
+	if len(codes) == 0 {
+		return []models.CountryMetadata{}, nil
+	}
+	return loaderFrom(p.Context).loadMany(codes), nil
+}
+
+func stringArg(p graphql.ResolveParams, name string) string {
+	s, _ := p.Args[name].(string)
+	return s
+}
+
+// fieldError is a resolver error, reported with the error code of the
+// matching REST problem document in its extensions. Like problem details,
+// the messages of internal and upstream errors are generic.
+type fieldError struct {
+	err     error
+	code    string
+	message string
+}
+
+// resolverError wraps a service error for a resolver, logging the cause of
+// a redacted one.
+func resolverError(err error) *fieldError {
+	message, redacted := service.PublicMessage(err)
+	if redacted {
+		log.Printf("graphql: %v", err)
+	}
+	return &fieldError{err: err, code: service.ErrorCode(err), message: message}
+}
+
+func (e *fieldError) Error() string {
+	return e.message
+}
+
+func (e *fieldError) Unwrap() error {
+	return e.err
+}
+
+func (e *fieldError) Extensions() map[string]any {
+	ext := map[string]any{"code": e.code}
+	var validationErr *service.ValidationError
+	if errors.As(e.err, &validationErr) {
+		ext["field"] = validationErr.Field
+	}
+	var notFoundErr *service.NotFoundError
+	if errors.As(e.err, &notFoundErr) && len(notFoundErr.Suggestions) > 0 {
+		ext["suggestions"] = notFoundErr.Suggestions
+	}
+	return ext
+}
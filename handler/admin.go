@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/Prasang-money/searchSvc/service"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler serves the admin endpoints, which manage the cache of the
+// service. Every request must carry the admin token as a bearer token.
+type AdminHandler struct {
+	admin service.Admin
+	token string
+}
+
+func NewAdminHandler(admin service.Admin, token string) *AdminHandler {
+	return &AdminHandler{
+		admin: admin,
+		token: token,
+	}
+}
+
+// purgeResponse reports the entries removed from the cache.
+type purgeResponse struct {
+	Purged int `json:"purged"`
+}
+
+// warmResponse reports the countries loaded into the cache.
+type warmResponse struct {
+	Warmed int `json:"warmed"`
+}
+
+// Authorize rejects requests without the admin token with 401. An empty
+// token rejects every request.
+func (handler AdminHandler) Authorize() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || handler.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(handler.token)) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="searchSvc admin"`)
+			writeProblem(c, http.StatusUnauthorized, CodeUnauthorized, "a valid admin bearer token is required")
+			return
+		}
+		c.Next()
+	}
+}
+
+func (handler AdminHandler) CacheStatsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		render(c, http.StatusOK, handler.admin.CacheStats(), true)
+
+	}
+
+}
+
+func (handler AdminHandler) PurgeCacheHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		render(c, http.StatusOK, purgeResponse{Purged: handler.admin.PurgeCache()}, true)
+
+	}
+
+}
+
+func (handler AdminHandler) WarmCacheHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		warmed, err := handler.admin.WarmCache()
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		render(c, http.StatusOK, warmResponse{Warmed: warmed}, true)
+
+	}
+
+}
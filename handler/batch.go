@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Prasang-money/searchSvc/models"
+	"github.com/Prasang-money/searchSvc/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Modes of a batch request.
+const (
+	// BatchPartial answers every item, successful or not.
+	BatchPartial = "partial"
+	// BatchAtomic fails the whole batch when any item fails.
+	BatchAtomic = "atomic"
+)
+
+// maxBatchBody bounds the size of a batch request body.
+const maxBatchBody = 1 << 20
+
+type batchRequest struct {
+	Items []models.BatchItem `json:"items"`
+	Mode  string             `json:"mode"`
+}
+
+type batchResponse struct {
+	Results   []batchResult `json:"results"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+}
+
+func (r batchResponse) rows() any {
+	return r.Results
+}
+
+// batchResult echoes an item with either its country or its problem.
+type batchResult struct {
+	models.BatchItem
+	Country any             `json:"country,omitempty"`
+	Error   *models.Problem `json:"error,omitempty"`
+}
+
+func (handler Handler) BatchHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		var req batchRequest
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBatchBody)
+		if err := c.ShouldBindJSON(&req); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeProblem(c, http.StatusRequestEntityTooLarge, CodeBodyTooLarge,
+					fmt.Sprintf("the request body must not exceed %d bytes", tooLarge.Limit))
+				return
+			}
+			writeError(c, &service.ValidationError{Field: "body", Reason: "must be a JSON object with an items list"})
+			return
+		}
+		if req.Mode == "" {
+			req.Mode = BatchPartial
+		}
+		if req.Mode != BatchPartial && req.Mode != BatchAtomic {
+			writeError(c, &service.ValidationError{Field: "mode", Reason: `must be "partial" or "atomic"`})
+			return
+		}
+		proj, err := parseProjection(c)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+
+		results, err := handler.service.BatchLookup(req.Items, req.Mode == BatchAtomic, proj.options()...)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		resp := batchResponse{Results: make([]batchResult, len(results))}
+		for i, r := range results {
+			resp.Results[i].BatchItem = r.Item
+			if r.Err != nil {
+				problem := errorProblem(c, r.Err)
+				resp.Results[i].Error = &problem
+				resp.Failed++
+				continue
+			}
+			resp.Results[i].Country = proj.apply(*r.Country)
+			resp.Succeeded++
+		}
+		render(c, http.StatusOK, resp, true)
+
+	}
+
+}
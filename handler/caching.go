@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Prasang-money/searchSvc/service"
+	"github.com/gin-gonic/gin"
+)
+
+// renderCacheable renders v like render, for a country whose freshness is
+// known. The response carries a strong ETag of its body, the time the
+// country was fetched as Last-Modified and Cache-Control lifetimes derived
+// from its expiry. A request whose copy is still current gets 304 Not
+// Modified instead.
+func renderCacheable(c *gin.Context, v any, pretty bool, fresh service.Freshness) {
+	contentType, body, ok := encodeResponse(c, v, pretty)
+	if !ok {
+		return
+	}
+
+	etag := strongETag(body)
+	h := c.Writer.Header()
+	h.Set("ETag", etag)
+	if !fresh.Modified.IsZero() {
+		h.Set("Last-Modified", fresh.Modified.UTC().Format(http.TimeFormat))
+	}
+	h.Set("Cache-Control", cacheControl(fresh, time.Now()))
+
+	if notModified(c.Request, etag, fresh.Modified) {
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// strongETag identifies a response body byte for byte.
+func strongETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// cacheControl lets clients use a response for as long as the service keeps
+// its copy of the country, then for one more lifetime of that copy while
+// they revalidate in the background. Country data rarely changes, so a
+// stale response is almost always still right. Without a known expiry,
+// clients must revalidate every time.
+func cacheControl(fresh service.Freshness, now time.Time) string {
+	if fresh.Expires.IsZero() {
+		return "no-cache"
+	}
+	maxAge := max(fresh.Expires.Sub(now), 0)
+	lifetime := max(fresh.Expires.Sub(fresh.Modified), 0)
+	return fmt.Sprintf("public, max-age=%d, stale-while-revalidate=%d",
+		int(maxAge/time.Second), int(lifetime/time.Second))
+}
+
+// notModified evaluates the conditional headers of a GET or HEAD request.
+// If-None-Match takes precedence over If-Modified-Since, as in RFC 9110.
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, etag)
+	}
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || modified.IsZero() {
+		return false
+	}
+	// HTTP dates have a resolution of one second
+	return !modified.Truncate(time.Second).After(ims)
+}
+
+// etagMatches reports whether an If-None-Match list names etag, using the
+// weak comparison the header calls for.
+func etagMatches(list, etag string) bool {
+	for _, tag := range strings.Split(list, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
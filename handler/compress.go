@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+)
+
+// minCompressSize is the smallest response body worth compressing. Smaller
+// bodies barely shrink and would cost more to compress than to send.
+const minCompressSize = 1024
+
+// compressibleTypes lists the media types of the responses that are
+// compressed. Binary formats such as protobuf are sent as they are.
+var compressibleTypes = map[string]bool{
+	"application/json":   true,
+	ProblemContentType:   true,
+	"application/xml":    true,
+	"text/xml":           true,
+	"text/csv":           true,
+	"application/yaml":   true,
+	"application/x-yaml": true,
+	"text/yaml":          true,
+	NDJSONContentType:    true,
+	"text/plain":         true,
+}
+
+// compressor is a content coding, pooling its writers.
+type compressor struct {
+	name string
+	pool sync.Pool
+}
+
+// flushWriter is a compressing writer that can be reused for another
+// response.
+type flushWriter interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// compressors are the supported content codings, in order of preference
+// when a client accepts several equally.
+var compressors = []*compressor{
+	{name: "zstd", pool: sync.Pool{New: func() any {
+		w, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		return w
+	}}},
+	{name: "br", pool: sync.Pool{New: func() any {
+		return brotli.NewWriterLevel(nil, brotli.DefaultCompression)
+	}}},
+	{name: "gzip", pool: sync.Pool{New: func() any {
+		return gzip.NewWriter(nil)
+	}}},
+}
+
+// Compress compresses response bodies with the zstd, br or gzip content
+// coding negotiated from Accept-Encoding. Only bodies of compressibleTypes
+// are compressed, and only from minCompressSize bytes on, unless the
+// handler flushes first: a flushed body is streamed, so it is compressed
+// whatever its size.
+//
+// A compressed body is a different representation, so its strong ETag is
+// suffixed with the coding, as in "abc-gzip". The suffix is removed from
+// If-None-Match before the handler sees it.
+func (handler Handler) Compress() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		comp := negotiateCoding(c.GetHeader("Accept-Encoding"))
+		w := &compressWriter{ResponseWriter: c.Writer, compressor: comp}
+		if inm := c.GetHeader("If-None-Match"); inm != "" {
+			var suffix string
+			if comp != nil {
+				suffix = comp.name
+			}
+			tags, suffixed := stripETagSuffixes(inm, suffix)
+			c.Request.Header.Set("If-None-Match", tags)
+			w.suffixed = suffixed
+		}
+
+		c.Writer = w
+		defer func() {
+			w.close()
+			c.Writer = w.ResponseWriter
+		}()
+		c.Next()
+	}
+}
+
+// negotiateCoding picks the accepted content coding with the highest
+// q-value, or nil to send the body as it is.
+func negotiateCoding(acceptEncoding string) *compressor {
+	q := make(map[string]float64)
+	wildcard := 0.0
+	// Accept-Encoding lists codings with q-values, like Accept media ranges
+	for _, r := range parseAccept(acceptEncoding) {
+		if r.mediaType == "*" {
+			wildcard = r.q
+		} else {
+			q[r.mediaType] = r.q
+		}
+	}
+
+	var best *compressor
+	bestQ := 0.0
+	for _, comp := range compressors {
+		weight, ok := q[comp.name]
+		if !ok {
+			weight = wildcard
+		}
+		if weight > bestQ {
+			best, bestQ = comp, weight
+		}
+	}
+	return best
+}
+
+// stripETagSuffixes removes the content coding suffixes Compress adds to
+// ETags from an If-None-Match list. It reports whether a tag carried the
+// suffix of the negotiated coding.
+func stripETagSuffixes(list, negotiated string) (string, bool) {
+	tags := strings.Split(list, ",")
+	suffixed := false
+	for i, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		for _, comp := range compressors {
+			if stripped, ok := strings.CutSuffix(tag, "-"+comp.name+`"`); ok {
+				tag = stripped + `"`
+				suffixed = suffixed || comp.name == negotiated
+				break
+			}
+		}
+		tags[i] = tag
+	}
+	return strings.Join(tags, ", "), suffixed
+}
+
+// compressWriter holds back the start of a body until it knows whether to
+// compress it: once minCompressSize bytes are written, the handler flushes
+// or the handler returns.
+type compressWriter struct {
+	gin.ResponseWriter
+	compressor *compressor
+	// suffixed tells whether the request named an ETag of a body compressed
+	// with the negotiated coding
+	suffixed bool
+
+	buf     []byte
+	decided bool
+	enc     flushWriter
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.buf = append(w.buf, data...)
+		if len(w.buf) >= minCompressSize {
+			if err := w.decide(true); err != nil {
+				return 0, err
+			}
+		}
+		return len(data), nil
+	}
+	if w.enc != nil {
+		return w.enc.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		_ = w.decide(false)
+	}
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.decide(true)
+	}
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Written reports whether the response is started, including a body still
+// held back.
+func (w *compressWriter) Written() bool {
+	return len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+// decide sets the headers of the response, starts it and writes the body
+// held back so far, compressed if compress is set and the response
+// qualifies.
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+	h := w.Header()
+	status := w.Status()
+
+	if status == http.StatusNotModified {
+		// a 304 stands for the representation the request named
+		h.Add("Vary", "Accept-Encoding")
+		if w.suffixed && w.compressor != nil {
+			setETagSuffix(h, w.compressor.name)
+		}
+	} else if compressible(h) && bodyAllowed(status) {
+		h.Add("Vary", "Accept-Encoding")
+		if compress && w.compressor != nil {
+			h.Set("Content-Encoding", w.compressor.name)
+			h.Del("Content-Length")
+			setETagSuffix(h, w.compressor.name)
+			w.enc = w.compressor.pool.Get().(flushWriter)
+			w.enc.Reset(w.ResponseWriter)
+		}
+	}
+
+	w.ResponseWriter.WriteHeaderNow()
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// close finishes the response after the handler returns.
+func (w *compressWriter) close() {
+	if !w.decided {
+		_ = w.decide(len(w.buf) >= minCompressSize)
+	}
+	if w.enc != nil {
+		_ = w.enc.Close()
+		// do not keep the response writer alive in the pool
+		w.enc.Reset(io.Discard)
+		w.compressor.pool.Put(w.enc)
+		w.enc = nil
+	}
+}
+
+// compressible tells whether a response of the media type in h should be
+// compressed. Responses already encoded by the handler are left alone.
+func compressible(h http.Header) bool {
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	return err == nil && compressibleTypes[mediaType]
+}
+
+func bodyAllowed(status int) bool {
+	return status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified
+}
+
+// setETagSuffix marks a strong ETag as the one of the compressed body.
+func setETagSuffix(h http.Header, coding string) {
+	etag := h.Get("ETag")
+	if strings.HasPrefix(etag, `"`) && strings.HasSuffix(etag, `"`) && len(etag) > 1 {
+		h.Set("ETag", strings.TrimSuffix(etag, `"`)+"-"+coding+`"`)
+	}
+}
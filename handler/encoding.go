@@ -0,0 +1,512 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"gopkg.in/yaml.v3"
+)
+
+// Encoder writes response bodies in one media type.
+type Encoder interface {
+	// Encode writes v to w. Pretty asks for indented output, in the formats
+	// that have a choice.
+	Encode(w io.Writer, v any, pretty bool) error
+}
+
+// encoding is a registered media type and the encoder serving it.
+type encoding struct {
+	mediaType   string
+	contentType string
+	encoder     Encoder
+}
+
+// encodings are the media types responses can be negotiated to. A wildcard
+// Accept range picks the first one that matches, so JSON comes first.
+var encodings = []encoding{
+	{"application/json", "application/json; charset=utf-8", jsonEncoder{}},
+	{"application/xml", "application/xml; charset=utf-8", xmlEncoder{}},
+	{"text/xml", "text/xml; charset=utf-8", xmlEncoder{}},
+	{"text/csv", "text/csv; charset=utf-8", csvEncoder{}},
+	{"application/yaml", "application/yaml; charset=utf-8", yamlEncoder{}},
+	{"application/x-yaml", "application/x-yaml; charset=utf-8", yamlEncoder{}},
+	{"text/yaml", "text/yaml; charset=utf-8", yamlEncoder{}},
+	{"application/x-protobuf", "application/x-protobuf; messageType=google.protobuf.Value", protobufEncoder{}},
+	{"application/protobuf", "application/protobuf; messageType=google.protobuf.Value", protobufEncoder{}},
+}
+
+// RegisterEncoder makes responses available in another media type, or
+// replaces the encoder of a registered one. It must be called before the
+// router serves requests.
+func RegisterEncoder(mediaType, contentType string, enc Encoder) {
+	mediaType = strings.ToLower(mediaType)
+	for i, e := range encodings {
+		if e.mediaType == mediaType {
+			encodings[i] = encoding{mediaType, contentType, enc}
+			return
+		}
+	}
+	encodings = append(encodings, encoding{mediaType, contentType, enc})
+}
+
+// mediaRange is one entry of an Accept header.
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+func (r mediaRange) matches(mediaType string) bool {
+	if r.mediaType == "*/*" || r.mediaType == mediaType {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(r.mediaType, "/*")
+	return ok && strings.HasPrefix(mediaType, prefix+"/")
+}
+
+// parseAccept parses an Accept header into its media ranges, most preferred
+// first. Ranges of equal quality keep the order they were listed in.
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		r := mediaRange{mediaType: strings.ToLower(strings.TrimSpace(params[0])), q: 1}
+		if r.mediaType == "" {
+			continue
+		}
+		for _, param := range params[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(name, "q") {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					r.q = q
+				}
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+	return ranges
+}
+
+// negotiate picks the encoding of a response from the Accept header. A
+// missing header accepts JSON. Media types refused with q=0 are never
+// picked, even through a wildcard.
+func negotiate(accept string) (encoding, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return encodings[0], true
+	}
+
+	ranges := parseAccept(accept)
+	refused := make(map[string]bool)
+	for _, r := range ranges {
+		if r.q <= 0 {
+			refused[r.mediaType] = true
+		}
+	}
+	for _, r := range ranges {
+		if r.q <= 0 {
+			break
+		}
+		for _, e := range encodings {
+			if r.matches(e.mediaType) && !refused[e.mediaType] {
+				return e, true
+			}
+		}
+	}
+	return encoding{}, false
+}
+
+// render writes v in the media type negotiated from the Accept header, or
+// answers 406 when none is supported. The pretty query parameter overrides
+// the indentation default of the endpoint.
+func render(c *gin.Context, status int, v any, pretty bool) {
+	contentType, body, ok := encodeResponse(c, v, pretty)
+	if !ok {
+		return
+	}
+	c.Data(status, contentType, body)
+}
+
+// encodeResponse encodes v for render and sets the Vary header. On failure
+// it writes the problem document and returns false.
+func encodeResponse(c *gin.Context, v any, pretty bool) (string, []byte, bool) {
+	enc, ok := negotiate(c.GetHeader("Accept"))
+	if !ok {
+		writeProblem(c, http.StatusNotAcceptable, CodeNotAcceptable,
+			"none of the accepted media types is supported: "+c.GetHeader("Accept"))
+		return "", nil, false
+	}
+	if p, err := optionalBoolQuery(c, "pretty"); err != nil {
+		writeError(c, err)
+		return "", nil, false
+	} else if p != nil {
+		pretty = *p
+	}
+
+	var buf bytes.Buffer
+	if err := enc.encoder.Encode(&buf, v, pretty); err != nil {
+		writeError(c, fmt.Errorf("encoding %s response: %w", enc.mediaType, err))
+		return "", nil, false
+	}
+	c.Writer.Header().Add("Vary", "Accept")
+	return enc.contentType, buf.Bytes(), true
+}
+
+type jsonEncoder struct{}
+
+func (jsonEncoder) Encode(w io.Writer, v any, pretty bool) error {
+	var data []byte
+	var err error
+	if pretty {
+		data, err = json.MarshalIndent(v, "", "    ")
+	} else {
+		data, err = json.Marshal(v)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// The other encoders work on the JSON form of a response, decoded into a
+// tree that keeps the order of object members, so every format names and
+// projects fields the same way.
+
+// object is a JSON object with its members in document order.
+type object []member
+
+type member struct {
+	key   string
+	value any
+}
+
+// toTree converts v to its JSON form: an object, a []any, a string, a
+// json.Number, a bool or nil.
+func toTree(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return decodeTree(dec)
+}
+
+func decodeTree(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		obj := object{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeTree(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, member{key: key.(string), value: value})
+		}
+		_, err = dec.Token()
+		return obj, err
+	case json.Delim('['):
+		list := []any{}
+		for dec.More() {
+			value, err := decodeTree(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err = dec.Token()
+		return list, err
+	default:
+		return tok, nil
+	}
+}
+
+// scalarText renders a JSON scalar as text.
+func scalarText(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// xmlEncoder writes a <response> element. Object members become child
+// elements and list entries <item> elements.
+type xmlEncoder struct{}
+
+func (xmlEncoder) Encode(w io.Writer, v any, pretty bool) error {
+	tree, err := toTree(v)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	if pretty {
+		enc.Indent("", "    ")
+	}
+	if err := encodeXML(enc, "response", tree); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+func encodeXML(enc *xml.Encoder, name string, v any) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case object:
+		for _, m := range v {
+			if err := encodeXML(enc, m.key, m.value); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if err := encodeXML(enc, "item", item); err != nil {
+				return err
+			}
+		}
+	default:
+		if err := enc.EncodeToken(xml.CharData(scalarText(v))); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// yamlEncoder writes a YAML document with object members in order.
+type yamlEncoder struct{}
+
+func (yamlEncoder) Encode(w io.Writer, v any, _ bool) error {
+	tree, err := toTree(v)
+	if err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(yamlNode(tree)); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func yamlNode(v any) *yaml.Node {
+	switch v := v.(type) {
+	case object:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for _, m := range v {
+			node.Content = append(node.Content, yamlNode(m.key), yamlNode(m.value))
+		}
+		return node
+	case []any:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range v {
+			node.Content = append(node.Content, yamlNode(item))
+		}
+		return node
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(v)}
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(v.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.String()}
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: scalarText(v)}
+	}
+}
+
+// tabular is implemented by responses that wrap a list, such as a page of
+// countries, so their CSV form lists the rows rather than the wrapper.
+type tabular interface {
+	rows() any
+}
+
+// csvEncoder writes a header row and one row per list entry, or a single
+// row for an object. Each member of an entry is a column, its value a cell
+// as rendered by csvCell; exports write their rows the same way.
+type csvEncoder struct{}
+
+func (csvEncoder) Encode(w io.Writer, v any, _ bool) error {
+	if t, ok := v.(tabular); ok {
+		v = t.rows()
+	}
+	tree, err := toTree(v)
+	if err != nil {
+		return err
+	}
+
+	var entries []object
+	switch tree := tree.(type) {
+	case []any:
+		for _, entry := range tree {
+			obj, ok := entry.(object)
+			if !ok {
+				obj = object{{key: "value", value: entry}}
+			}
+			entries = append(entries, obj)
+		}
+	case object:
+		entries = []object{tree}
+	default:
+		entries = []object{{{key: "value", value: tree}}}
+	}
+
+	var columns []string
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		for _, m := range entry {
+			if !seen[m.key] {
+				seen[m.key] = true
+				columns = append(columns, m.key)
+			}
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := cw.Write(csvRecord(entry, columns)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvRecord renders the members of row named by columns as CSV cells, in
+// the order of columns. Missing members are empty cells.
+func csvRecord(row object, columns []string) []string {
+	values := make(map[string]any, len(row))
+	for _, m := range row {
+		values[m.key] = m.value
+	}
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		record[i] = csvCell(values[column])
+	}
+	return record
+}
+
+// csvCell renders a JSON value as a CSV cell. Lists are joined with ";",
+// objects in a list being represented by their first member, such as the
+// code of a currency. Other objects, such as flags or translations, are
+// listed as key=value pairs.
+func csvCell(v any) string {
+	switch v := v.(type) {
+	case []any:
+		cells := make([]string, len(v))
+		for i, item := range v {
+			if obj, ok := item.(object); ok {
+				if len(obj) > 0 {
+					cells[i] = csvCell(obj[0].value)
+				}
+				continue
+			}
+			cells[i] = csvCell(item)
+		}
+		return strings.Join(cells, ";")
+	case object:
+		pairs := make([]string, len(v))
+		for i, m := range v {
+			pairs[i] = m.key + "=" + csvCell(m.value)
+		}
+		return strings.Join(pairs, ";")
+	default:
+		return scalarText(v)
+	}
+}
+
+// protobufEncoder writes the response as a google.protobuf.Value message,
+// the protobuf form of a JSON document. Map fields are marshaled in a fixed
+// order, so equal responses get equal bodies and ETags.
+type protobufEncoder struct{}
+
+func (protobufEncoder) Encode(w io.Writer, v any, _ bool) error {
+	tree, err := toTree(v)
+	if err != nil {
+		return err
+	}
+	value, err := structValue(tree)
+	if err != nil {
+		return err
+	}
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func structValue(v any) (*structpb.Value, error) {
+	switch v := v.(type) {
+	case object:
+		fields := make(map[string]*structpb.Value, len(v))
+		for _, m := range v {
+			value, err := structValue(m.value)
+			if err != nil {
+				return nil, err
+			}
+			fields[m.key] = value
+		}
+		return structpb.NewStructValue(&structpb.Struct{Fields: fields}), nil
+	case []any:
+		values := make([]*structpb.Value, len(v))
+		for i, item := range v {
+			value, err := structValue(item)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return structpb.NewListValue(&structpb.ListValue{Values: values}), nil
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return structpb.NewNumberValue(f), nil
+	case string:
+		return structpb.NewStringValue(v), nil
+	case bool:
+		return structpb.NewBoolValue(v), nil
+	default:
+		return structpb.NewNullValue(), nil
+	}
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Prasang-money/searchSvc/models"
+	"github.com/Prasang-money/searchSvc/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Error codes reported in models.Problem. Those of service errors are
+// defined by the service.
+const (
+	CodeNotFound            = service.CodeNotFound
+	CodeInvalidInput        = service.CodeInvalidInput
+	CodeUpstreamUnavailable = service.CodeUpstreamUnavailable
+	CodeUpstreamBadResponse = service.CodeUpstreamBadResponse
+	CodeTimeout             = service.CodeTimeout
+	CodeRouteNotFound       = "route_not_found"
+	CodeMethodNotAllowed    = "method_not_allowed"
+	CodeNotAcceptable       = "not_acceptable"
+	CodeBodyTooLarge        = "body_too_large"
+	CodeUnauthorized        = "unauthorized"
+	CodeInternal            = service.CodeInternal
+)
+
+// ProblemContentType is the media type of RFC 7807 error documents.
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix is joined with an error code to form the problem type URI.
+const problemTypePrefix = "urn:searchsvc:problem:"
+
+// codeStatus maps the codes of service errors to HTTP statuses.
+var codeStatus = map[string]int{
+	CodeNotFound:            http.StatusNotFound,
+	CodeInvalidInput:        http.StatusBadRequest,
+	CodeUpstreamUnavailable: http.StatusServiceUnavailable,
+	CodeUpstreamBadResponse: http.StatusBadGateway,
+	CodeTimeout:             http.StatusGatewayTimeout,
+	CodeInternal:            http.StatusInternalServerError,
+}
+
+// errorStatus maps a service error to its HTTP status and error code.
+func errorStatus(err error) (int, string) {
+	code := service.ErrorCode(err)
+	return codeStatus[code], code
+}
+
+// writeError aborts the request with the problem document matching err.
+func writeError(c *gin.Context, err error) {
+	abortWithProblem(c, errorProblem(c, err))
+}
+
+// errorProblem builds the problem document matching err. Internal and
+// upstream errors get a generic detail; their cause is attached to the
+// request, for the request log.
+func errorProblem(c *gin.Context, err error) models.Problem {
+	status, code := errorStatus(err)
+	detail, redacted := service.PublicMessage(err)
+	if redacted {
+		_ = c.Error(err)
+	}
+	problem := newProblem(c, status, code, detail)
+
+	var validationErr *service.ValidationError
+	if errors.As(err, &validationErr) {
+		name := validationErr.Field
+		// name the parameter of the failed item of a batch
+		var batchErr *service.BatchError
+		if errors.As(err, &batchErr) {
+			name = fmt.Sprintf("items[%d].%s", batchErr.Index, name)
+		}
+		problem.InvalidParams = []models.InvalidParam{{
+			Name:   name,
+			Reason: validationErr.Reason,
+		}}
+	}
+
+	var notFoundErr *service.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		problem.Suggestions = notFoundErr.Suggestions
+	}
+	return problem
+}
+
+// writeProblem aborts the request with an RFC 7807 problem document.
+func writeProblem(c *gin.Context, status int, code, detail string) {
+	abortWithProblem(c, newProblem(c, status, code, detail))
+}
+
+func newProblem(c *gin.Context, status int, code, detail string) models.Problem {
+	return models.Problem{
+		Type:      problemTypePrefix + code,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  c.Request.URL.RequestURI(),
+		Code:      code,
+		RequestID: c.GetString(RequestIDKey),
+	}
+}
+
+func abortWithProblem(c *gin.Context, problem models.Problem) {
+	c.Header("Content-Type", ProblemContentType)
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// NoRoute answers requests for unknown paths.
+func (handler Handler) NoRoute() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writeProblem(c, http.StatusNotFound, CodeRouteNotFound, "no route matches "+c.Request.URL.Path)
+	}
+}
+
+// NoMethod answers requests using a method the matched path does not support.
+func (handler Handler) NoMethod() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writeProblem(c, http.StatusMethodNotAllowed, CodeMethodNotAllowed,
+			c.Request.Method+" is not allowed on "+c.Request.URL.Path)
+	}
+}
@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Prasang-money/searchSvc/models"
+	"github.com/Prasang-money/searchSvc/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Formats of the export endpoint.
+const (
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+)
+
+// Content types of the export formats.
+const (
+	NDJSONContentType = "application/x-ndjson"
+	CSVContentType    = "text/csv; charset=utf-8"
+)
+
+// compactFields are the columns of a CSV export in the compact view.
+var compactFields = []string{"name", "population", "capital", "currency"}
+
+// recordWriter writes one exported country at a time.
+type recordWriter interface {
+	Write(meta models.CountryMetadata) error
+	Flush() error
+}
+
+func (handler Handler) ExportHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		format, ok := c.GetQuery("format")
+		if !ok {
+			if format, ok = exportFormat(c.GetHeader("Accept")); !ok {
+				writeProblem(c, http.StatusNotAcceptable, CodeNotAcceptable,
+					"exports are served as "+NDJSONContentType+" or text/csv")
+				return
+			}
+		}
+		if format != FormatNDJSON && format != FormatCSV {
+			writeError(c, &service.ValidationError{Field: "format", Reason: `must be "ndjson" or "csv"`})
+			return
+		}
+		proj, err := parseProjection(c)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+
+		var w recordWriter
+		if format == FormatCSV {
+			w = newCSVWriter(c.Writer, proj)
+		} else {
+			w = &ndjsonWriter{enc: json.NewEncoder(c.Writer), flusher: c.Writer, proj: proj}
+		}
+
+		// Headers go out with the first record. Errors after that can
+		// only cut the stream short.
+		started := false
+		start := func() {
+			if !started {
+				started = true
+				c.Header("Content-Type", exportContentType(format))
+				c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="countries.%s"`, format))
+				c.Status(http.StatusOK)
+			}
+		}
+		err = handler.service.ExportCountries(func(meta models.CountryMetadata) error {
+			start()
+			return w.Write(meta)
+		})
+		if err != nil && !started {
+			writeError(c, err)
+			return
+		}
+		if err == nil {
+			start()
+			err = w.Flush()
+		}
+		if err != nil {
+			_ = c.Error(err)
+		}
+
+	}
+
+}
+
+// exportFormat picks the export format from the Accept header when the
+// format parameter is absent. NDJSON is the default.
+func exportFormat(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return FormatNDJSON, true
+	}
+	for _, r := range parseAccept(accept) {
+		switch {
+		case r.q <= 0:
+			return "", false
+		case r.matches(NDJSONContentType):
+			return FormatNDJSON, true
+		case r.matches("text/csv"):
+			return FormatCSV, true
+		}
+	}
+	return "", false
+}
+
+func exportContentType(format string) string {
+	if format == FormatCSV {
+		return CSVContentType
+	}
+	return NDJSONContentType
+}
+
+// ndjsonWriter writes each country as a JSON document on its own line.
+type ndjsonWriter struct {
+	enc     *json.Encoder
+	flusher http.Flusher
+	proj    projection
+}
+
+func (w *ndjsonWriter) Write(meta models.CountryMetadata) error {
+	if err := w.enc.Encode(w.proj.apply(meta)); err != nil {
+		return err
+	}
+	w.flusher.Flush()
+	return nil
+}
+
+func (w *ndjsonWriter) Flush() error {
+	w.flusher.Flush()
+	return nil
+}
+
+// csvWriter writes each country as a row, after a header row of the
+// projected fields. Cells are rendered like those of negotiated CSV
+// responses.
+type csvWriter struct {
+	w       *csv.Writer
+	flusher http.Flusher
+	proj    projection
+	columns []string
+	header  bool
+}
+
+func newCSVWriter(w gin.ResponseWriter, proj projection) *csvWriter {
+	columns := proj.fields
+	if len(columns) == 0 {
+		columns = compactFields
+		if proj.view == ViewFull {
+			columns = models.MetadataFields()
+		}
+	}
+	return &csvWriter{w: csv.NewWriter(w), flusher: w, proj: proj, columns: columns}
+}
+
+func (w *csvWriter) writeHeader() error {
+	if w.header {
+		return nil
+	}
+	w.header = true
+	return w.w.Write(w.columns)
+}
+
+func (w *csvWriter) Write(meta models.CountryMetadata) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+
+	meta.Name = meta.LocalizedName(w.proj.lang)
+	tree, err := toTree(meta.Project(w.columns))
+	if err != nil {
+		return err
+	}
+	if err := w.w.Write(csvRecord(tree.(object), w.columns)); err != nil {
+		return err
+	}
+	w.w.Flush()
+	w.flusher.Flush()
+	return w.w.Error()
+}
+
+// Flush writes the header row even when no country was exported.
+func (w *csvWriter) Flush() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.w.Flush()
+	w.flusher.Flush()
+	return w.w.Error()
+}
@@ -2,11 +2,20 @@ package handler
 
 import (
 	"net/http"
+	"strings"
 
+	"github.com/Prasang-money/searchSvc/models"
 	"github.com/Prasang-money/searchSvc/service"
 	"github.com/gin-gonic/gin"
 )
 
+// Response views, selected with the view query parameter. The compact view
+// is the original flat shape and stays the default of V1.
+const (
+	ViewCompact = "compact"
+	ViewFull    = "full"
+)
+
 type Handler struct {
 	service service.ServiceInterface
 }
@@ -28,14 +37,360 @@ func (handler Handler) SearchHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		countryName := c.Query("name")
-		resp, err := handler.service.SearchCountries(countryName)
+		if err := service.ValidateName("name", countryName); err != nil {
+			writeError(c, err)
+			return
+		}
+		proj, err := parseProjection(c)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+
+		var fresh service.Freshness
+		opts := append(proj.options(), service.WithFreshness(&fresh))
+		resp, err := handler.service.SearchCountries(countryName, opts...)
+
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		renderCacheable(c, proj.apply(*resp), true, fresh)
+
+	}
+
+}
+
+func (handler Handler) SearchByCodeHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		code := c.Query("code")
+		if err := service.ValidateCode("code", code); err != nil {
+			writeError(c, err)
+			return
+		}
+		proj, err := parseProjection(c)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+
+		resp, err := handler.service.SearchByCode(code, proj.options()...)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		render(c, http.StatusOK, proj.apply(*resp), true)
+
+	}
+
+}
+
+func (handler Handler) SearchByCapitalHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		capital := c.Query("capital")
+		if err := service.ValidateName("capital", capital); err != nil {
+			writeError(c, err)
+			return
+		}
+		proj, err := parseProjection(c)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+
+		resp, err := handler.service.SearchByCapital(capital, proj.options()...)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		render(c, http.StatusOK, proj.apply(*resp), true)
+
+	}
+
+}
+
+func (handler Handler) SearchByCurrencyHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		currency := c.Query("currency")
+		if err := service.ValidateCurrency("currency", currency); err != nil {
+			writeError(c, err)
+			return
+		}
+		proj, err := parseProjection(c)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+
+		resp, err := handler.service.SearchByCurrency(currency, proj.options()...)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		result := make([]any, len(resp))
+		for i, match := range resp {
+			result[i] = proj.applyMatch(match)
+		}
+		render(c, http.StatusOK, result, true)
+
+	}
+
+}
+
+func (handler Handler) FuzzySearchHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		query := c.Query("q")
+		if err := service.ValidateName("q", query); err != nil {
+			writeError(c, err)
+			return
+		}
+		threshold, err := floatQuery(c, "threshold", service.DefaultFuzzyThreshold)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		limit, err := intQuery(c, "limit", defaultLimit, maxLimit)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		proj, err := parseProjection(c)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
 
+		resp, err := handler.service.FuzzySearch(query, threshold, limit)
 		if err != nil {
-			c.IndentedJSON(http.StatusInternalServerError, err.Error())
+			writeError(c, err)
+			return
+		}
+		result := make([]any, len(resp))
+		for i, match := range resp {
+			result[i] = proj.applyFuzzy(match)
+		}
+		render(c, http.StatusOK, result, true)
+
+	}
+
+}
+
+// listResponse is a page of countries with the links to browse the others.
+type listResponse struct {
+	Data       []any     `json:"data"`
+	Total      int       `json:"total"`
+	NextCursor string    `json:"nextCursor,omitempty"`
+	Links      listLinks `json:"links"`
+}
+
+type listLinks struct {
+	Self string `json:"self"`
+	Next string `json:"next,omitempty"`
+}
+
+func (r listResponse) rows() any {
+	return r.Data
+}
+
+func (handler Handler) ListHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		query, err := parseListQuery(c)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		proj, err := parseProjection(c)
+		if err != nil {
+			writeError(c, err)
 			return
 		}
-		c.IndentedJSON(http.StatusOK, *resp)
 
+		page, err := handler.service.ListCountries(query)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		resp := listResponse{
+			Data:       make([]any, len(page.Countries)),
+			Total:      page.Total,
+			NextCursor: page.NextCursor,
+			Links:      listLinks{Self: c.Request.URL.RequestURI()},
+		}
+		for i, hit := range page.Countries {
+			resp.Data[i] = proj.applyHit(hit)
+		}
+		if page.NextCursor != "" {
+			next := *c.Request.URL
+			params := next.Query()
+			params.Set("cursor", page.NextCursor)
+			next.RawQuery = params.Encode()
+			resp.Links.Next = next.RequestURI()
+		}
+		render(c, http.StatusOK, resp, true)
+
+	}
+
+}
+
+// parseListQuery reads the search terms, filters, sort order and page of a
+// list request.
+func parseListQuery(c *gin.Context) (service.ListQuery, error) {
+	q := service.ListQuery{
+		Query:  c.Query("q"),
+		Sort:   c.Query("sort"),
+		Cursor: c.Query("cursor"),
+		Filter: service.Filter{
+			Region:   c.Query("region"),
+			Language: c.Query("language"),
+			Currency: c.Query("currency"),
+		},
+	}
+
+	var err error
+	if q.Limit, err = intQuery(c, "limit", defaultLimit, maxLimit); err != nil {
+		return q, err
+	}
+	if q.Filter.MinPopulation, err = optionalIntQuery(c, "minPopulation"); err != nil {
+		return q, err
+	}
+	if q.Filter.MaxPopulation, err = optionalIntQuery(c, "maxPopulation"); err != nil {
+		return q, err
 	}
+	if q.Filter.Landlocked, err = optionalBoolQuery(c, "landlocked"); err != nil {
+		return q, err
+	}
+	return q, nil
+}
 
+func (handler Handler) SuggestHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		prefix := c.Query("q")
+		if err := service.ValidateName("q", prefix); err != nil {
+			writeError(c, err)
+			return
+		}
+		limit, err := intQuery(c, "limit", defaultLimit, maxLimit)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+
+		resp, err := handler.service.Suggest(prefix, limit)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		render(c, http.StatusOK, resp, false)
+
+	}
+
+}
+
+// projection describes how countries are rendered: in the compact or the
+// full view, or as an explicit list of fields.
+type projection struct {
+	view   string
+	fields []string
+	lang   string
+}
+
+// parseProjection reads the view and fields query parameters and negotiates
+// the language of country names. The view defaults to the one of the API
+// version; fields, when present, take precedence over it.
+func parseProjection(c *gin.Context) (projection, error) {
+	view := c.DefaultQuery("view", apiVersion(c).DefaultView)
+	if view != ViewCompact && view != ViewFull {
+		return projection{}, &service.ValidationError{Field: "view", Reason: `must be "compact" or "full"`}
+	}
+
+	var fields []string
+	for _, f := range strings.Split(c.Query("fields"), ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	if err := service.ValidateFields("fields", fields); err != nil {
+		return projection{}, err
+	}
+	lang := negotiateLanguage(c)
+	c.Header("Content-Language", lang)
+	// names are localized from Accept-Language, so shared caches must key on it
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	return projection{view: view, fields: fields, lang: lang}, nil
+}
+
+// options passes the projection on to the service, so only the requested
+// fields, and the translations of a localized name, are fetched from
+// upstream.
+func (p projection) options() []service.Option {
+	if len(p.fields) == 0 {
+		return nil
+	}
+	return []service.Option{service.WithFields(p.fields...), service.WithLanguage(p.lang)}
+}
+
+func (p projection) apply(meta models.CountryMetadata) any {
+	meta.Name = meta.LocalizedName(p.lang)
+	switch {
+	case len(p.fields) > 0:
+		return meta.Project(p.fields)
+	case p.view == ViewCompact:
+		return meta.Compact()
+	default:
+		return meta
+	}
+}
+
+// applyMatch renders a currency search result. The matched currencies are
+// always included, whatever the projection.
+func (p projection) applyMatch(match models.CurrencyMatch) any {
+	match.Name = match.LocalizedName(p.lang)
+	if len(p.fields) > 0 {
+		result := match.Project(p.fields)
+		result["matchedCurrencies"] = match.MatchedCurrencies
+		return result
+	}
+	if p.view == ViewCompact {
+		match.CountryMetadata = match.Compact()
+	}
+	return match
+}
+
+// applyFuzzy renders a fuzzy search result. The matched name and score are
+// always included, whatever the projection.
+func (p projection) applyFuzzy(match models.FuzzyMatch) any {
+	match.Name = match.LocalizedName(p.lang)
+	if len(p.fields) > 0 {
+		result := match.Project(p.fields)
+		result["matchedName"] = match.MatchedName
+		result["score"] = match.Score
+		return result
+	}
+	if p.view == ViewCompact {
+		match.CountryMetadata = match.Compact()
+	}
+	return match
+}
+
+// applyHit renders a list query hit. The score of a search is included
+// whatever the projection.
+func (p projection) applyHit(hit models.SearchHit) any {
+	hit.Name = hit.LocalizedName(p.lang)
+	if len(p.fields) > 0 {
+		result := hit.Project(p.fields)
+		if hit.Score != 0 {
+			result["score"] = hit.Score
+		}
+		return result
+	}
+	if p.view == ViewCompact {
+		hit.CountryMetadata = hit.Compact()
+	}
+	return hit
 }
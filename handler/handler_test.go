@@ -1,41 +1,55 @@
 package handler
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/Prasang-money/searchSvc/internal/servicetest"
 	"github.com/Prasang-money/searchSvc/models"
+	"github.com/Prasang-money/searchSvc/service"
+	"github.com/andybalholm/brotli"
 	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
-// MockService is a mock implementation of the Service interface
-type MockService struct {
-	mock.Mock
-}
-
-func (m *MockService) SearchCountries(name string) (*models.CountryMetadata, error) {
-	args := m.Called(name)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.CountryMetadata), args.Error(1)
-}
-
 func setupTestRouter(handler *Handler) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(handler.RequestID(), handler.Recovery())
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(handler.NoRoute())
+	router.NoMethod(handler.NoMethod())
 	router.GET("/health", handler.HealthCheck())
 	router.GET("/search", handler.SearchHandler())
+	router.GET("/by-code", handler.SearchByCodeHandler())
+	router.GET("/by-capital", handler.SearchByCapitalHandler())
+	router.GET("/by-currency", handler.SearchByCurrencyHandler())
+	router.GET("/fuzzy", handler.FuzzySearchHandler())
+	router.GET("/countries", handler.ListHandler())
+	router.GET("/suggest", handler.SuggestHandler())
+	router.POST("/batch", handler.BatchHandler())
+	router.GET("/export", handler.ExportHandler())
+	router.GET("/panic", func(c *gin.Context) { panic("boom") })
 	return router
 }
 
 func TestHealthCheck(t *testing.T) {
-	mockService := new(MockService)
+	mockService := new(servicetest.Mock)
 	handler := NewHandler(mockService)
 	router := setupTestRouter(handler)
 
@@ -52,7 +66,7 @@ func TestHealthCheck(t *testing.T) {
 }
 
 func TestSearchHandler_Success(t *testing.T) {
-	mockService := new(MockService)
+	mockService := new(servicetest.Mock)
 	handler := NewHandler(mockService)
 	router := setupTestRouter(handler)
 
@@ -89,7 +103,7 @@ func TestSearchHandler_Success(t *testing.T) {
 }
 
 func TestSearchHandler_Error(t *testing.T) {
-	mockService := new(MockService)
+	mockService := new(servicetest.Mock)
 	handler := NewHandler(mockService)
 	router := setupTestRouter(handler)
 
@@ -103,44 +117,1397 @@ func TestSearchHandler_Error(t *testing.T) {
 	// Assert HTTP status code
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 
-	// Assert error message
-	assert.Contains(t, w.Body.String(), expectedError.Error())
+	// Assert the internal error is not disclosed
+	assert.NotContains(t, w.Body.String(), expectedError.Error())
 
 	// Verify that the mock was called as expected
 	mockService.AssertExpectations(t)
 }
 
+func TestSearchHandler_ErrorMapping(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   int
+		wantErr    string
+		wantDetail string
+	}{
+		{"not found", fmt.Errorf("%w: Atlantis", service.ErrNotFound), http.StatusNotFound, CodeNotFound, "country not found: Atlantis"},
+		{"invalid input", service.ErrInvalidInput, http.StatusBadRequest, CodeInvalidInput, "invalid input"},
+		// internal and upstream causes are not sent to clients
+		{"upstream unavailable", fmt.Errorf("%w: Get \"https://restcountries.com/v3.1/name/Atlantis\": dial tcp: connection refused", service.ErrUpstreamUnavailable),
+			http.StatusServiceUnavailable, CodeUpstreamUnavailable, "the country data source is unavailable"},
+		{"upstream bad response", fmt.Errorf("%w: status 500", service.ErrUpstreamBadResponse), http.StatusBadGateway, CodeUpstreamBadResponse, "the country data source sent an invalid response"},
+		{"timeout", service.ErrTimeout, http.StatusGatewayTimeout, CodeTimeout, "the country data source did not answer in time"},
+		{"unknown", fmt.Errorf("boom"), http.StatusInternalServerError, CodeInternal, "internal server error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(servicetest.Mock)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			mockService.On("SearchCountries", "Atlantis").Return(nil, tt.err)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/search?name=Atlantis", nil)
+			req.Header.Set(RequestIDHeader, "req-123")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+
+			var response models.Problem
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, "urn:searchsvc:problem:"+tt.wantErr, response.Type)
+			assert.Equal(t, http.StatusText(tt.wantCode), response.Title)
+			assert.Equal(t, tt.wantCode, response.Status)
+			assert.Equal(t, tt.wantDetail, response.Detail)
+			assert.Equal(t, "/search?name=Atlantis", response.Instance)
+			assert.Equal(t, tt.wantErr, response.Code)
+			assert.Equal(t, "req-123", response.RequestID)
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProblemResponses(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		wantCode int
+		wantErr  string
+	}{
+		{"unknown route", "GET", "/nowhere", http.StatusNotFound, CodeRouteNotFound},
+		{"method not allowed", "POST", "/search", http.StatusMethodNotAllowed, CodeMethodNotAllowed},
+		{"panic", "GET", "/panic", http.StatusInternalServerError, CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(new(servicetest.Mock))
+			router := setupTestRouter(handler)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+
+			var response models.Problem
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, "urn:searchsvc:problem:"+tt.wantErr, response.Type)
+			assert.Equal(t, http.StatusText(tt.wantCode), response.Title)
+			assert.Equal(t, tt.wantCode, response.Status)
+			assert.NotEmpty(t, response.Detail)
+			assert.NotContains(t, response.Detail, "boom")
+			assert.Equal(t, tt.path, response.Instance)
+			assert.Equal(t, tt.wantErr, response.Code)
+
+			// A request ID is generated when the client does not send one
+			assert.NotEmpty(t, response.RequestID)
+			assert.Equal(t, response.RequestID, w.Header().Get(RequestIDHeader))
+		})
+	}
+}
+
 func TestSearchHandler_EmptyQuery(t *testing.T) {
-	mockService := new(MockService)
+	mockService := new(servicetest.Mock)
 	handler := NewHandler(mockService)
 	router := setupTestRouter(handler)
 
-	expectedResponse := &models.CountryMetadata{}
-	mockService.On("SearchCountries", "").Return(expectedResponse, nil)
-
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/search", nil)
 	router.ServeHTTP(w, req)
 
 	// Assert HTTP status code
-	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
 
 	// Parse response body
+	var response models.Problem
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	// Assert the missing parameter is reported
+	assert.Equal(t, CodeInvalidInput, response.Code)
+	assert.Equal(t, []models.InvalidParam{{Name: "name", Reason: "is required"}}, response.InvalidParams)
+
+	// The service must not be called with invalid input
+	mockService.AssertNotCalled(t, "SearchCountries", mock.Anything)
+}
+
+func TestSearchHandler_InvalidName(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"blank", "name=%20%20"},
+		{"too long", "name=" + strings.Repeat("a", service.MaxNameLength+1)},
+		{"slash", "name=India%2Fcapital"},
+		{"question mark", "name=India%3Fx=1"},
+		{"percent", "name=India%2525"},
+		{"digits", "name=India1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(servicetest.Mock)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/search?"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var response models.Problem
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, CodeInvalidInput, response.Code)
+			if assert.Len(t, response.InvalidParams, 1) {
+				assert.Equal(t, "name", response.InvalidParams[0].Name)
+				assert.NotEmpty(t, response.InvalidParams[0].Reason)
+			}
+
+			mockService.AssertNotCalled(t, "SearchCountries", mock.Anything)
+		})
+	}
+}
+
+func TestSearchByCodeHandler_Success(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	handler := NewHandler(mockService)
+	router := setupTestRouter(handler)
+
+	expectedResponse := &models.CountryMetadata{
+		Name:       "India",
+		Population: 1380004385,
+		Capital:    "New Delhi",
+		Currency:   "₹",
+	}
+	mockService.On("SearchByCode", "IN").Return(expectedResponse, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/by-code?code=IN", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
 	var response models.CountryMetadata
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
+	assert.Equal(t, *expectedResponse, response)
 
-	// Assert empty response
-	assert.Equal(t, models.CountryMetadata{}, response)
+	mockService.AssertExpectations(t)
+}
+
+func TestSearchByCodeHandler_InvalidCode(t *testing.T) {
+	for _, code := range []string{"", "I", "INDI", "I1", "12", "../"} {
+		t.Run(code, func(t *testing.T) {
+			mockService := new(servicetest.Mock)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/by-code?code="+url.QueryEscape(code), nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockService.AssertNotCalled(t, "SearchByCode", mock.Anything)
+		})
+	}
+}
+
+func TestSearchByCapitalHandler(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	handler := NewHandler(mockService)
+	router := setupTestRouter(handler)
+
+	expectedResponse := &models.CountryMetadata{
+		Name:       "Australia",
+		Population: 25687041,
+		Capital:    "Canberra",
+		Currency:   "$",
+	}
+	mockService.On("SearchByCapital", "canberra").Return(expectedResponse, nil)
+	mockService.On("SearchByCapital", "Atlantis").Return(nil, service.ErrNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/by-capital?capital=canberra", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.CountryMetadata
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, *expectedResponse, response)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/by-capital?capital=Atlantis", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/by-capital", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestSearchByCurrencyHandler(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	handler := NewHandler(mockService)
+	router := setupTestRouter(handler)
+
+	euro := models.Currency{Code: "EUR", Name: "Euro", Symbol: "€"}
+	expectedResponse := []models.CurrencyMatch{
+		{CountryMetadata: models.CountryMetadata{Name: "Austria", Currency: "€"}, MatchedCurrencies: []models.Currency{euro}},
+		{CountryMetadata: models.CountryMetadata{Name: "Belgium", Currency: "€"}, MatchedCurrencies: []models.Currency{euro}},
+	}
+	mockService.On("SearchByCurrency", "€").Return(expectedResponse, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/by-currency?currency="+url.QueryEscape("€"), nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []models.CurrencyMatch
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedResponse, response)
+
+	for _, bad := range []string{"", "EUR/1", "E U R", "12"} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/by-currency?currency="+url.QueryEscape(bad), nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, bad)
+	}
+
+	mockService.AssertExpectations(t)
+}
+
+func TestSearchHandler_Views(t *testing.T) {
+	full := &models.CountryMetadata{
+		Name:       "South Africa",
+		Population: 59308690,
+		Capital:    "Pretoria",
+		Currency:   "R",
+		Capitals:   []string{"Pretoria", "Bloemfontein", "Cape Town"},
+		Currencies: []models.Currency{{Code: "ZAR", Name: "South African rand", Symbol: "R"}},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode int
+		want     models.CountryMetadata
+	}{
+		{"default is compact", "", http.StatusOK, full.Compact()},
+		{"compact", "&view=compact", http.StatusOK, full.Compact()},
+		{"full", "&view=full", http.StatusOK, *full},
+		{"unknown", "&view=everything", http.StatusBadRequest, models.CountryMetadata{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(servicetest.Mock)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+			mockService.On("SearchCountries", "South Africa").Return(full, nil)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/search?name=South%20Africa"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantCode != http.StatusOK {
+				mockService.AssertNotCalled(t, "SearchCountries", mock.Anything)
+				return
+			}
+
+			var response models.CountryMetadata
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, response)
+		})
+	}
+}
+
+func TestSearchHandler_Fields(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	handler := NewHandler(mockService)
+	router := setupTestRouter(handler)
+
+	mockService.On("SearchCountries", "Germany").Return(&servicetest.Germany, nil)
+
+	// The default response does not grow with the new fields
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/search?name=Germany", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "region")
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/search?name=Germany&fields=name,region,languages,borders,flags", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]any
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Len(t, response, 5)
+	assert.Equal(t, "Germany", response["name"])
+	assert.Equal(t, "Europe", response["region"])
+	assert.Equal(t, []any{map[string]any{"code": "deu", "name": "German"}}, response["languages"])
+	assert.Equal(t, []any{"AUT", "CHE", "FRA"}, response["borders"])
+	assert.Equal(t, map[string]any{"png": "https://flagcdn.com/w320/de.png", "svg": "https://flagcdn.com/de.svg"}, response["flags"])
+
+	mockService.AssertExpectations(t)
+}
+
+func TestSearchHandler_UnknownField(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	handler := NewHandler(mockService)
+	router := setupTestRouter(handler)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/search?name=Germany&fields=name,motto", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response models.Problem
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	if assert.Len(t, response.InvalidParams, 1) {
+		assert.Equal(t, "fields", response.InvalidParams[0].Name)
+		assert.Contains(t, response.InvalidParams[0].Reason, "motto")
+	}
+
+	mockService.AssertNotCalled(t, "SearchCountries", mock.Anything)
+}
+
+func TestSearchHandler_Language(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		acceptLanguage string
+		wantName       string
+		wantLanguage   string
+	}{
+		{"default", "", "", "Germany", "en"},
+		{"accept language", "", "fr-CH, fr;q=0.9, en;q=0.8", "Allemagne", "fr"},
+		{"quality ordering", "", "en;q=0.5, de;q=0.9", "Deutschland", "de"},
+		{"lang parameter wins", "&lang=de", "fr", "Deutschland", "de"},
+		{"unsupported falls back", "&lang=xx", "", "Germany", "en"},
+		{"missing translation falls back", "", "ja", "Germany", "ja"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(servicetest.Mock)
+			handler := NewHandler(mockService)
+			router := setupTestRouter(handler)
+			mockService.On("SearchCountries", "Germany").Return(&servicetest.Germany, nil)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/search?name=Germany"+tt.query, nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.wantLanguage, w.Header().Get("Content-Language"))
+
+			var response models.CountryMetadata
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantName, response.Name)
+			assert.Equal(t, "Berlin", response.Capital)
+		})
+	}
+}
+
+func TestLocalizedResponses_VaryOnLanguage(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	handler := NewHandler(mockService)
+	router := setupTestRouter(handler)
+	mockService.On("SearchByCode", "DE").Return(&servicetest.Germany, nil)
+	mockService.On("SearchByCapital", "Berlin").Return(&servicetest.Germany, nil)
+	mockService.On("SearchByCurrency", "EUR").Return([]models.CurrencyMatch{{CountryMetadata: servicetest.Germany}}, nil)
+	mockService.On("ListCountries", mock.Anything).Return(&service.Page{Countries: []models.SearchHit{{CountryMetadata: servicetest.Germany}}}, nil)
+
+	for _, target := range []string{"/by-code?code=DE", "/by-capital?capital=Berlin", "/by-currency?currency=EUR", "/countries"} {
+		t.Run(target, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", target, nil)
+			req.Header.Set("Accept-Language", "fr")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "fr", w.Header().Get("Content-Language"))
+			assert.Contains(t, w.Header().Values("Vary"), "Accept-Language")
+			assert.Contains(t, w.Body.String(), "Allemagne")
+		})
+	}
+}
+
+func TestFuzzySearchHandler(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	handler := NewHandler(mockService)
+	router := setupTestRouter(handler)
+
+	expectedResponse := []models.FuzzyMatch{{
+		CountryMetadata: models.CountryMetadata{Name: "Philippines", Capital: "Manila", Region: "Asia"},
+		MatchedName:     "Philippines",
+		Score:           0.82,
+	}}
+	mockService.On("FuzzySearch", "Phillipines", service.DefaultFuzzyThreshold, 10).Return(expectedResponse, nil)
+	mockService.On("FuzzySearch", "Phillipines", 0.5, 3).Return(expectedResponse, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/fuzzy?q=Phillipines", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []models.FuzzyMatch
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	if assert.Len(t, response, 1) {
+		assert.Equal(t, "Philippines", response[0].Name)
+		assert.Equal(t, 0.82, response[0].Score)
+		assert.Empty(t, response[0].Region)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/fuzzy?q=Phillipines&threshold=0.5&limit=3&fields=name", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var projected []map[string]any
+	err = json.Unmarshal(w.Body.Bytes(), &projected)
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"name": "Philippines", "matchedName": "Philippines", "score": 0.82}}, projected)
+
+	for _, bad := range []string{"q=", "q=Phillipines&threshold=1.5", "q=Phillipines&threshold=x", "q=Phillipines&limit=0", "q=Phillipines&limit=500"} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/fuzzy?"+bad, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, bad)
+	}
+
+	mockService.AssertExpectations(t)
+}
+
+func TestSearchHandler_Suggestions(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	handler := NewHandler(mockService)
+	router := setupTestRouter(handler)
+
+	notFound := &service.NotFoundError{Query: "Columbia", Suggestions: []string{"Colombia"}}
+	mockService.On("SearchCountries", "Columbia").Return(nil, notFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/search?name=Columbia", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	var response models.Problem
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, CodeNotFound, response.Code)
+	assert.Equal(t, []string{"Colombia"}, response.Suggestions)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestSearchHandler_Caching(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	handler := NewHandler(mockService)
+	router := setupTestRouter(handler)
+
+	modified := time.Now().Add(-time.Hour).Truncate(time.Second)
+	mockService.Freshness = service.Freshness{Modified: modified, Expires: modified.Add(3 * time.Hour)}
+	mockService.On("SearchCountries", "Germany").Return(&models.CountryMetadata{Name: "Germany", Population: 83240525}, nil)
+
+	get := func(header http.Header) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/search?name=Germany", nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get(nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, etag)
+	assert.Equal(t, modified.UTC().Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+	// Two hours are left of a three hour lifetime
+	assert.Regexp(t, `^public, max-age=(7199|7200), stale-while-revalidate=10800$`, w.Header().Get("Cache-Control"))
+	assert.Equal(t, []string{"Accept-Language", "Accept"}, w.Header().Values("Vary"))
+
+	// The ETag depends on the body only
+	assert.Equal(t, etag, get(nil).Header().Get("ETag"))
+	assert.NotEqual(t, etag, get(http.Header{"Accept": {"application/xml"}}).Header().Get("ETag"))
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   int
+	}{
+		{"matching etag", http.Header{"If-None-Match": {etag}}, http.StatusNotModified},
+		{"etag in a list", http.Header{"If-None-Match": {`"other", W/` + etag}}, http.StatusNotModified},
+		{"any etag", http.Header{"If-None-Match": {"*"}}, http.StatusNotModified},
+		{"other etag", http.Header{"If-None-Match": {`"other"`}}, http.StatusOK},
+		{"not modified since", http.Header{"If-Modified-Since": {modified.UTC().Format(http.TimeFormat)}}, http.StatusNotModified},
+		{"modified since", http.Header{"If-Modified-Since": {modified.Add(-time.Second).UTC().Format(http.TimeFormat)}}, http.StatusOK},
+		{"malformed date", http.Header{"If-Modified-Since": {"yesterday"}}, http.StatusOK},
+		{"etag wins over date", http.Header{
+			"If-None-Match":     {`"other"`},
+			"If-Modified-Since": {modified.UTC().Format(http.TimeFormat)},
+		}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(tt.header)
+			assert.Equal(t, tt.want, w.Code)
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+			if tt.want == http.StatusNotModified {
+				assert.Empty(t, w.Body.String())
+			}
+		})
+	}
+
+	// Without a known expiry clients revalidate every time
+	mockService.Freshness = service.Freshness{Modified: modified}
+	assert.Equal(t, "no-cache", get(nil).Header().Get("Cache-Control"))
+
+	// An expired copy can still be served while revalidating
+	mockService.Freshness = service.Freshness{Modified: modified, Expires: modified.Add(time.Minute)}
+	assert.Equal(t, "public, max-age=0, stale-while-revalidate=60", get(nil).Header().Get("Cache-Control"))
+}
+
+func TestSuggestHandler(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	handler := NewHandler(mockService)
+	router := setupTestRouter(handler)
+
+	expectedResponse := []models.Suggestion{
+		{Name: "Germany", MatchedName: "Germany", Population: 83240525},
+	}
+	mockService.On("Suggest", "ger", 10).Return(expectedResponse, nil)
+	mockService.On("Suggest", "ger", 5).Return(expectedResponse, nil)
+
+	for _, query := range []string{"q=ger", "q=ger&limit=5"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/suggest?"+query, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response []models.Suggestion
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedResponse, response)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/suggest", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestListHandler(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	handler := NewHandler(mockService)
+	router := setupTestRouter(handler)
+
+	minPop, landlocked := 1000000, true
+	query := service.ListQuery{
+		Query:  "swiss franc",
+		Sort:   "-population",
+		Limit:  1,
+		Filter: service.Filter{Region: "Europe", MinPopulation: &minPop, Landlocked: &landlocked},
+	}
+	page := &service.Page{
+		Countries: []models.SearchHit{
+			{CountryMetadata: models.CountryMetadata{Name: "Switzerland", Population: 8654622, Capital: "Bern", Currency: "Fr.", Region: "Europe"}, Score: 6},
+		},
+		Total:      2,
+		NextCursor: "next",
+	}
+	mockService.On("ListCountries", query).Return(page, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/countries?q=swiss+franc&region=Europe&minPopulation=1000000&landlocked=true&sort=-population&limit=1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data       []models.SearchHit `json:"data"`
+		Total      int                `json:"total"`
+		NextCursor string             `json:"nextCursor"`
+		Links      map[string]string  `json:"links"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.SearchHit{
+		{CountryMetadata: models.CountryMetadata{Name: "Switzerland", Population: 8654622, Capital: "Bern", Currency: "Fr."}, Score: 6},
+	}, response.Data)
+	assert.Equal(t, 2, response.Total)
+	assert.Equal(t, "next", response.NextCursor)
+	assert.Equal(t, "/countries?q=swiss+franc&region=Europe&minPopulation=1000000&landlocked=true&sort=-population&limit=1", response.Links["self"])
+	assert.Equal(t, "/countries?cursor=next&landlocked=true&limit=1&minPopulation=1000000&q=swiss+franc&region=Europe&sort=-population", response.Links["next"])
+
+	// The last page has no next link, and projections keep the score
+	mockService.On("ListCountries", service.ListQuery{Cursor: "next", Limit: 10}).Return(&service.Page{Countries: page.Countries, Total: 2}, nil)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/countries?cursor=next&fields=name,region", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var projected struct {
+		Data  []map[string]any  `json:"data"`
+		Links map[string]string `json:"links"`
+	}
+	err = json.Unmarshal(w.Body.Bytes(), &projected)
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"name": "Switzerland", "region": "Europe", "score": float64(6)}}, projected.Data)
+	assert.NotContains(t, projected.Links, "next")
+
+	invalidSort := &service.ValidationError{Field: "sort", Reason: "is invalid"}
+	mockService.On("ListCountries", service.ListQuery{Sort: "bogus", Limit: 10}).Return(nil, invalidSort)
+
+	for _, bad := range []string{"limit=0", "minPopulation=-1", "maxPopulation=x", "landlocked=maybe", "fields=bogus", "sort=bogus"} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/countries?"+bad, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, bad)
+	}
+
+	mockService.AssertExpectations(t)
+}
+
+func TestBatchHandler(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	handler := NewHandler(mockService)
+	router := setupTestRouter(handler)
+
+	items := []models.BatchItem{{Name: "India"}, {Code: "XX"}}
+	results := []service.BatchResult{
+		{Item: items[0], Country: &models.CountryMetadata{Name: "India", Population: 1380004385, Capital: "New Delhi", Currency: "₹", Region: "Asia"}},
+		{Item: items[1], Err: fmt.Errorf("%w: XX", service.ErrNotFound)},
+	}
+	mockService.On("BatchLookup", items, false).Return(results, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/batch", strings.NewReader(`{"items":[{"name":"India"},{"code":"XX"}]}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Results []struct {
+			Name    string                  `json:"name"`
+			Code    string                  `json:"code"`
+			Country *models.CountryMetadata `json:"country"`
+			Error   *models.Problem         `json:"error"`
+		} `json:"results"`
+		Succeeded int `json:"succeeded"`
+		Failed    int `json:"failed"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, response.Succeeded)
+	assert.Equal(t, 1, response.Failed)
+	assert.Equal(t, "India", response.Results[0].Name)
+	assert.Equal(t, &models.CountryMetadata{Name: "India", Population: 1380004385, Capital: "New Delhi", Currency: "₹"}, response.Results[0].Country)
+	assert.Nil(t, response.Results[0].Error)
+	assert.Equal(t, "XX", response.Results[1].Code)
+	assert.Nil(t, response.Results[1].Country)
+	assert.Equal(t, http.StatusNotFound, response.Results[1].Error.Status)
+	assert.Equal(t, CodeNotFound, response.Results[1].Error.Code)
+
+	// An all-or-nothing batch fails as a whole with the status of the item
+	batchErr := &service.BatchError{Index: 1, Err: &service.ValidationError{Field: "code", Reason: "is invalid"}}
+	mockService.On("BatchLookup", items, true).Return(nil, batchErr)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/batch", strings.NewReader(`{"items":[{"name":"India"},{"code":"XX"}],"mode":"atomic"}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+	var problem models.Problem
+	err = json.Unmarshal(w.Body.Bytes(), &problem)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.InvalidParam{{Name: "items[1].code", Reason: "is invalid"}}, problem.InvalidParams)
+
+	for _, bad := range []string{``, `[]`, `{"items":"India"}`, `{"items":[{"name":"India"}],"mode":"some"}`} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", "/batch", strings.NewReader(bad))
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, bad)
+	}
+
+	mockService.AssertExpectations(t)
+}
+
+func TestBatchHandler_BodyTooLarge(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	handler := NewHandler(mockService)
+	router := setupTestRouter(handler)
+
+	body := `{"items":[{"name":"` + strings.Repeat("a", maxBatchBody) + `"}]}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/batch", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+	var problem models.Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, CodeBodyTooLarge, problem.Code)
+	assert.Equal(t, fmt.Sprintf("the request body must not exceed %d bytes", maxBatchBody), problem.Detail)
+	mockService.AssertNotCalled(t, "BatchLookup", mock.Anything, mock.Anything)
+}
+
+func TestExportHandler(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	handler := NewHandler(mockService)
+	router := setupTestRouter(handler)
+
+	countries := []models.CountryMetadata{
+		{Name: "Germany", Population: 83240525, Capital: "Berlin", Currency: "€", Languages: []models.Language{{Code: "deu", Name: "German"}}, Translations: map[string]string{"fra": "Allemagne"}},
+		{Name: "Switzerland", Population: 8654622, Capital: "Bern", Currency: "Fr.", Languages: []models.Language{{Code: "deu", Name: "German"}, {Code: "fra", Name: "French"}}, Translations: map[string]string{"fra": "Suisse"}},
+	}
+	mockService.On("ExportCountries").Return(countries, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/export", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, NDJSONContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="countries.ndjson"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, `{"name":"Germany","population":83240525,"capital":"Berlin","currency":"€"}`+"\n"+
+		`{"name":"Switzerland","population":8654622,"capital":"Bern","currency":"Fr."}`+"\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/export?format=csv&fields=name,languages,translations&lang=fr", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, CSVContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, "name,languages,translations\n"+
+		"Allemagne,deu,fra=Allemagne\n"+
+		"Suisse,deu;fra,fra=Suisse\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/export?format=xml", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestExportHandler_CSVMatchesNegotiatedCSV(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	handler := NewHandler(mockService)
+	router := setupTestRouter(handler)
+
+	germany := servicetest.Germany
+	mockService.On("SearchCountries", "Germany").Return(&germany, nil)
+	mockService.On("ExportCountries").Return([]models.CountryMetadata{germany}, nil)
+
+	const fields = "name,capitals,currencies,languages,flags,translations,latlng"
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/search?name=Germany&fields="+fields, nil)
+	req.Header.Set("Accept", "text/csv")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	negotiated := w.Body.String()
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/export?format=csv&fields="+fields, nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	exported := w.Body.String()
+
+	// Both flatten the same country into the same cells; only the order of
+	// the columns differs, following the fields parameter in exports.
+	records := func(body string) map[string]string {
+		rows, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+		assert.NoError(t, err)
+		assert.Len(t, rows, 2)
+		record := make(map[string]string)
+		for i, column := range rows[0] {
+			record[column] = rows[1][i]
+		}
+		return record
+	}
+	assert.Equal(t, records(negotiated), records(exported))
+	assert.Equal(t, "png="+germany.Flags.PNG+";svg="+germany.Flags.SVG, records(exported)["flags"])
+	assert.Equal(t, "EUR", records(exported)["currencies"])
+}
+
+func TestExportHandler_Errors(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	handler := NewHandler(mockService)
+	router := setupTestRouter(handler)
+
+	// Failures before the first record are reported as problems
+	mockService.On("ExportCountries").Return(nil, fmt.Errorf("%w: down", service.ErrUpstreamUnavailable)).Once()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/export", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+
+	// An empty CSV export still has its header row
+	mockService.On("ExportCountries").Return([]models.CountryMetadata{}, nil).Once()
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/export?format=csv", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "name,population,capital,currency\n", w.Body.String())
+
+	mockService.AssertExpectations(t)
+}
+
+func TestContentNegotiation(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	handler := NewHandler(mockService)
+	router := setupTestRouter(handler)
+
+	country := &models.CountryMetadata{Name: "India", Population: 1380004385, Capital: "New Delhi", Currency: "₹"}
+	mockService.On("SearchCountries", "India").Return(country, nil)
+
+	tests := []struct {
+		accept      string
+		query       string
+		contentType string
+		body        string
+	}{
+		{"", "", "application/json; charset=utf-8", "{\n    \"name\": \"India\",\n    \"population\": 1380004385,\n    \"capital\": \"New Delhi\",\n    \"currency\": \"₹\"\n}"},
+		{"application/json", "&pretty=false", "application/json; charset=utf-8", `{"name":"India","population":1380004385,"capital":"New Delhi","currency":"₹"}`},
+		{"application/xml", "&pretty=false", "application/xml; charset=utf-8", xml.Header + `<response><name>India</name><population>1380004385</population><capital>New Delhi</capital><currency>₹</currency></response>`},
+		{"text/csv", "", "text/csv; charset=utf-8", "name,population,capital,currency\nIndia,1380004385,New Delhi,₹\n"},
+		{"application/yaml", "", "application/yaml; charset=utf-8", "name: India\npopulation: 1380004385\ncapital: New Delhi\ncurrency: ₹\n"},
+		{"application/xml;q=0.5, text/csv", "", "text/csv; charset=utf-8", "name,population,capital,currency\nIndia,1380004385,New Delhi,₹\n"},
+		{"text/*", "&pretty=false", "text/xml; charset=utf-8", xml.Header + `<response><name>India</name><population>1380004385</population><capital>New Delhi</capital><currency>₹</currency></response>`},
+		{"application/json;q=0, */*", "&pretty=false", "application/xml; charset=utf-8", xml.Header + `<response><name>India</name><population>1380004385</population><capital>New Delhi</capital><currency>₹</currency></response>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/search?name=India"+tt.query, nil)
+			req.Header.Set("Accept", tt.accept)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, []string{"Accept-Language", "Accept"}, w.Header().Values("Vary"))
+			assert.Equal(t, tt.body, w.Body.String())
+		})
+	}
+
+	// Protobuf responses are google.protobuf.Value messages
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/search?name=India", nil)
+	req.Header.Set("Accept", "application/x-protobuf")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var value structpb.Value
+	assert.NoError(t, proto.Unmarshal(w.Body.Bytes(), &value))
+	assert.Equal(t, map[string]any{"name": "India", "population": float64(1380004385), "capital": "New Delhi", "currency": "₹"}, value.AsInterface())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/search?name=India", nil)
+	req.Header.Set("Accept", "image/png")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+	var problem models.Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, CodeNotAcceptable, problem.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestContentNegotiation_ProtobufETag(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	handler := NewHandler(mockService)
+	router := setupTestRouter(handler)
+
+	germany := servicetest.Germany
+	mockService.Freshness = service.Freshness{Modified: time.Now().Add(-time.Hour)}
+	mockService.On("SearchCountries", "Germany").Return(&germany, nil)
+
+	// Encoding a value twice gives the same bytes
+	var first, second bytes.Buffer
+	assert.NoError(t, protobufEncoder{}.Encode(&first, germany, false))
+	assert.NoError(t, protobufEncoder{}.Encode(&second, germany, false))
+	assert.Equal(t, first.Bytes(), second.Bytes())
+
+	// so equal responses get the same ETag, and revalidate
+	get := func(header http.Header) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/search?name=Germany", nil)
+		req.Header = header
+		req.Header.Set("Accept", "application/x-protobuf")
+		router.ServeHTTP(w, req)
+		return w
+	}
+	w := get(http.Header{})
+	assert.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	for range 10 {
+		again := get(http.Header{})
+		assert.Equal(t, w.Body.Bytes(), again.Body.Bytes())
+		assert.Equal(t, etag, again.Header().Get("ETag"))
+	}
+	assert.Equal(t, http.StatusNotModified, get(http.Header{"If-None-Match": {etag}}).Code)
+}
+
+func TestContentNegotiation_Lists(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	handler := NewHandler(mockService)
+	router := setupTestRouter(handler)
+
+	page := &service.Page{
+		Countries: []models.SearchHit{
+			{CountryMetadata: models.CountryMetadata{Name: "Germany", Capitals: []string{"Berlin"}, Currencies: []models.Currency{{Code: "EUR", Name: "Euro", Symbol: "€"}}, Flags: &models.Flags{PNG: "de.png"}}},
+			{CountryMetadata: models.CountryMetadata{Name: "South Africa", Capitals: []string{"Pretoria", "Bloemfontein", "Cape Town"}}},
+		},
+		Total: 2,
+	}
+	mockService.On("ListCountries", service.ListQuery{Limit: 10}).Return(page, nil)
+
+	// CSV lists the rows of a page, a column per field
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/countries?fields=name,capitals,currencies,flags", nil)
+	req.Header.Set("Accept", "text/csv")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "capitals,currencies,flags,name\n"+
+		"Berlin,EUR,png=de.png,Germany\n"+
+		"Pretoria;Bloemfontein;Cape Town,,,South Africa\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/countries", nil)
+	req.Header.Set("Accept", "application/yaml")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `data:
+  - name: Germany
+    population: 0
+    capital: ""
+    currency: ""
+  - name: South Africa
+    population: 0
+    capital: ""
+    currency: ""
+total: 2
+links:
+  self: /countries
+`, w.Body.String())
+
+	// Exports pick CSV from the Accept header when no format is given
+	mockService.On("ExportCountries").Return([]models.CountryMetadata{{Name: "Germany"}}, nil)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/export?fields=name", nil)
+	req.Header.Set("Accept", "text/csv")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "name\nGermany\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/export", nil)
+	req.Header.Set("Accept", "application/xml")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
 
-	// Verify that the mock was called as expected
 	mockService.AssertExpectations(t)
 }
 
 func TestNewHandler(t *testing.T) {
-	mockService := new(MockService)
+	mockService := new(servicetest.Mock)
 	handler := NewHandler(mockService)
 
 	assert.NotNil(t, handler)
 	assert.Equal(t, mockService, handler.service)
 }
+
+func TestCompress(t *testing.T) {
+	handler := NewHandler(new(servicetest.Mock))
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(handler.Compress())
+
+	big := strings.Repeat(`{"name":"Germany","capital":"Berlin"},`, 100)
+	router.GET("/big", func(c *gin.Context) { c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(big)) })
+	router.GET("/small", func(c *gin.Context) { c.Data(http.StatusOK, "application/json", []byte(`{"name":"Germany"}`)) })
+	router.GET("/binary", func(c *gin.Context) { c.Data(http.StatusOK, "application/x-protobuf", []byte(big)) })
+	router.GET("/stream", func(c *gin.Context) {
+		c.Header("Content-Type", NDJSONContentType)
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString(`{"name":"Germany"}` + "\n")
+		c.Writer.Flush()
+		_, _ = c.Writer.WriteString(`{"name":"France"}` + "\n")
+	})
+
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		acceptEncoding string
+		want           string
+	}{
+		{"gzip", "gzip"},
+		{"br", "br"},
+		{"zstd", "zstd"},
+		{"gzip, deflate, br", "br"},
+		{"gzip;q=1.0, br;q=0.5", "gzip"},
+		{"*", "zstd"},
+		{"zstd;q=0, *", "br"},
+		{"gzip, *;q=0", "gzip"},
+		{"identity", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptEncoding, func(t *testing.T) {
+			w := get("/big", tt.acceptEncoding)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.want, w.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+			assert.Equal(t, big, decompress(t, tt.want, w.Body.Bytes()))
+			if tt.want != "" {
+				assert.Less(t, w.Body.Len(), len(big))
+			}
+		})
+	}
+
+	// Small bodies and binary types are sent as they are
+	w := get("/small", "gzip")
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+	assert.Equal(t, `{"name":"Germany"}`, w.Body.String())
+
+	w = get("/binary", "gzip")
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Empty(t, w.Header().Get("Vary"))
+	assert.Equal(t, big, w.Body.String())
+
+	// Streamed bodies are compressed whatever their size
+	w = get("/stream", "gzip")
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "{\"name\":\"Germany\"}\n{\"name\":\"France\"}\n", decompress(t, "gzip", w.Body.Bytes()))
+}
+
+func TestCompress_ETags(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	handler := NewHandler(mockService)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(handler.Compress())
+	router.GET("/search", handler.SearchHandler())
+
+	translations := make(map[string]string)
+	for _, lang := range models.TranslationKeys {
+		translations[lang] = strings.Repeat("Deutschland ", 5)
+	}
+	mockService.Freshness = service.Freshness{Modified: time.Now()}
+	mockService.On("SearchCountries", "Germany").Return(&models.CountryMetadata{Name: "Germany", Translations: translations}, nil)
+
+	get := func(acceptEncoding, ifNoneMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/search?name=Germany&view=full", nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	plain := get("", "").Header().Get("ETag")
+	compressed := get("gzip", "")
+	assert.Equal(t, "gzip", compressed.Header().Get("Content-Encoding"))
+	etag := compressed.Header().Get("ETag")
+	assert.Equal(t, strings.TrimSuffix(plain, `"`)+`-gzip"`, etag)
+
+	// Either ETag revalidates, and a 304 names the representation asked for
+	w := get("gzip", etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+	assert.Contains(t, w.Header().Values("Vary"), "Accept-Encoding")
+
+	w = get("", etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, plain, w.Header().Get("ETag"))
+
+	w = get("gzip", plain)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, plain, w.Header().Get("ETag"))
+}
+
+// decompress decodes a body sent with the given content coding.
+func decompress(t *testing.T, coding string, body []byte) string {
+	var r io.Reader = bytes.NewReader(body)
+	switch coding {
+	case "gzip":
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			t.Fatalf("reading gzip body: %v", err)
+		}
+		r = gz
+	case "br":
+		r = brotli.NewReader(r)
+	case "zstd":
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			t.Fatalf("reading zstd body: %v", err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("decompressing %s body: %v", coding, err)
+	}
+	return string(data)
+}
+
+// setupVersionedRouter serves the search and batch endpoints as V1, V2 and
+// unversioned, like route.GetRoute.
+func setupVersionedRouter(handler *Handler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	for prefix, v := range map[string]APIVersion{"/api": V1, "/api/v1": V1, "/api/v2": V2} {
+		api := router.Group(prefix, handler.Version(v, prefix))
+		api.GET("/countries/search", handler.SearchHandler())
+		api.POST("/countries/batch", handler.BatchHandler())
+	}
+	return router
+}
+
+func TestVersions_Search(t *testing.T) {
+	full := &models.CountryMetadata{
+		Name:       "South Africa",
+		Population: 59308690,
+		Capital:    "Pretoria",
+		Currency:   "R",
+		Capitals:   []string{"Pretoria", "Bloemfontein", "Cape Town"},
+		Currencies: []models.Currency{{Code: "ZAR", Name: "South African rand", Symbol: "R"}},
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		want           models.CountryMetadata
+		wantDeprecated bool
+	}{
+		{"unversioned is v1", "/api/countries/search?name=South%20Africa", full.Compact(), true},
+		{"v1 is flat", "/api/v1/countries/search?name=South%20Africa", full.Compact(), true},
+		{"v1 full view", "/api/v1/countries/search?name=South%20Africa&view=full", *full, true},
+		{"v2 is full", "/api/v2/countries/search?name=South%20Africa", *full, false},
+		{"v2 compact view", "/api/v2/countries/search?name=South%20Africa&view=compact", full.Compact(), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(servicetest.Mock)
+			handler := NewHandler(mockService)
+			router := setupVersionedRouter(handler)
+			mockService.On("SearchCountries", "South Africa").Return(full, nil)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var response models.CountryMetadata
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, response)
+
+			if !tt.wantDeprecated {
+				assert.Empty(t, w.Header().Get("Deprecation"))
+				assert.Empty(t, w.Header().Get("Sunset"))
+				assert.Empty(t, w.Header().Get("Link"))
+				return
+			}
+			assert.Equal(t, fmt.Sprintf("@%d", V1.Deprecated.Unix()), w.Header().Get("Deprecation"))
+			assert.Equal(t, "Sat, 01 May 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+			assert.Equal(t, `</api/v2/countries/search>; rel="successor-version"`, w.Header().Get("Link"))
+		})
+	}
+}
+
+func TestVersions_DeprecationOnErrors(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	handler := NewHandler(mockService)
+	router := setupVersionedRouter(handler)
+
+	// a request rejected before the service is called is still told about
+	// the sunset
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/countries/search", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Deprecation"))
+	assert.NotEmpty(t, w.Header().Get("Sunset"))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v2/countries/search", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, w.Header().Get("Deprecation"))
+	mockService.AssertNotCalled(t, "SearchCountries", mock.Anything)
+}
+
+func TestVersions_Batch(t *testing.T) {
+	full := servicetest.Germany
+
+	for _, tt := range []struct {
+		path string
+		want models.CountryMetadata
+	}{
+		{"/api/v1/countries/batch", full.Compact()},
+		{"/api/v2/countries/batch", full},
+	} {
+		t.Run(tt.path, func(t *testing.T) {
+			mockService := new(servicetest.Mock)
+			handler := NewHandler(mockService)
+			router := setupVersionedRouter(handler)
+			mockService.On("BatchLookup", mock.Anything, false).Return([]service.BatchResult{{Country: &full}}, nil)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", tt.path, strings.NewReader(`{"items":[{"name":"Germany"}]}`))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var response struct {
+				Results []struct {
+					Country models.CountryMetadata `json:"country"`
+				} `json:"results"`
+			}
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			if assert.Len(t, response.Results, 1) {
+				assert.Equal(t, tt.want, response.Results[0].Country)
+			}
+		})
+	}
+}
+
+func setupAdminRouter(admin *servicetest.Mock) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := NewAdminHandler(admin, "s3cret")
+	group := router.Group("/admin", handler.Authorize())
+	group.GET("/cache", handler.CacheStatsHandler())
+	group.DELETE("/cache", handler.PurgeCacheHandler())
+	group.POST("/cache/warm", handler.WarmCacheHandler())
+	return router
+}
+
+func TestAdminHandlers(t *testing.T) {
+	stats := models.CacheStats{Size: 8, Capacity: 2000, TTLSeconds: 21600, Hits: 5, Misses: 3}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		setup    func(m *servicetest.Mock)
+		wantCode int
+		wantBody string
+	}{
+		{"stats", "GET", "/admin/cache", func(m *servicetest.Mock) { m.On("CacheStats").Return(stats) }, http.StatusOK,
+			`{"size":8,"capacity":2000,"ttlSeconds":21600,"hits":5,"misses":3,"evictions":0,"expirations":0}`},
+		{"purge", "DELETE", "/admin/cache", func(m *servicetest.Mock) { m.On("PurgeCache").Return(8) }, http.StatusOK,
+			`{"purged":8}`},
+		{"warm", "POST", "/admin/cache/warm", func(m *servicetest.Mock) { m.On("WarmCache").Return(250, nil) }, http.StatusOK,
+			`{"warmed":250}`},
+		{"warm fails", "POST", "/admin/cache/warm", func(m *servicetest.Mock) {
+			m.On("WarmCache").Return(0, fmt.Errorf("warming cache: %w", service.ErrUpstreamUnavailable))
+		}, http.StatusServiceUnavailable, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAdmin := new(servicetest.Mock)
+			tt.setup(mockAdmin)
+			router := setupAdminRouter(mockAdmin)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer s3cret")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			} else {
+				assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+			}
+			mockAdmin.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminHandlers_Unauthorized(t *testing.T) {
+	for _, authorization := range []string{"", "Bearer wrong", "Basic czNjcmV0", "s3cret"} {
+		t.Run(authorization, func(t *testing.T) {
+			mockAdmin := new(servicetest.Mock)
+			router := setupAdminRouter(mockAdmin)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("DELETE", "/admin/cache", nil)
+			if authorization != "" {
+				req.Header.Set("Authorization", authorization)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer")
+			var problem models.Problem
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, CodeUnauthorized, problem.Code)
+			mockAdmin.AssertNotCalled(t, "PurgeCache")
+		})
+	}
+
+	// without a token, nobody is let in
+	router := gin.New()
+	router.GET("/admin/cache", NewAdminHandler(new(servicetest.Mock), "").Authorize(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/admin/cache", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"sort"
+
+	"github.com/Prasang-money/searchSvc/models"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// supportedLanguages lists the ISO 639-1 codes country names can be returned
+// in, with the default language first so the matcher falls back to it.
+var supportedLanguages = func() []string {
+	langs := []string{models.DefaultLanguage}
+	var translated []string
+	for lang := range models.TranslationKeys {
+		translated = append(translated, lang)
+	}
+	sort.Strings(translated)
+	return append(langs, translated...)
+}()
+
+var languageMatcher = func() language.Matcher {
+	tags := make([]language.Tag, len(supportedLanguages))
+	for i, lang := range supportedLanguages {
+		tags[i] = language.Make(lang)
+	}
+	return language.NewMatcher(tags)
+}()
+
+// negotiateLanguage picks the language of country names in the response.
+// The lang query parameter wins over the Accept-Language header; anything
+// unsupported or malformed falls back to English.
+func negotiateLanguage(c *gin.Context) string {
+	var tags []language.Tag
+	if lang := c.Query("lang"); lang != "" {
+		if tag, err := language.Parse(lang); err == nil {
+			tags = []language.Tag{tag}
+		}
+	} else {
+		tags, _, _ = language.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+	}
+
+	_, index, confidence := languageMatcher.Match(tags...)
+	if confidence == language.No {
+		return models.DefaultLanguage
+	}
+	return supportedLanguages[index]
+}
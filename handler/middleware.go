@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Prasang-money/searchSvc/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the request ID in both directions. An incoming
+// value is reused so IDs can be correlated across services.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDKey is the gin context key holding the request ID.
+const RequestIDKey = "requestID"
+
+// RequestID assigns every request an ID and echoes it in the response.
+func (handler Handler) RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" {
+			id = utils.NewRequestID()
+		}
+		c.Set(RequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// Recovery turns panics into a 500 problem document. The panic value is
+// logged by gin but never sent to the client.
+func (handler Handler) Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered any) {
+		writeProblem(c, http.StatusInternalServerError, CodeInternal, "internal server error")
+	})
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Prasang-money/searchSvc/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Bounds of the limit query parameter of list endpoints.
+const (
+	defaultLimit = 10
+	maxLimit     = 50
+)
+
+// intQuery reads an integer query parameter between 1 and max, returning def
+// when it is absent.
+func intQuery(c *gin.Context, name string, def, max int) (int, error) {
+	raw, ok := c.GetQuery(name)
+	if !ok {
+		return def, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil || v < 1 || v > max {
+		return 0, &service.ValidationError{Field: name, Reason: fmt.Sprintf("must be an integer between 1 and %d", max)}
+	}
+	return v, nil
+}
+
+// optionalIntQuery reads a non-negative integer query parameter, returning
+// nil when it is absent.
+func optionalIntQuery(c *gin.Context, name string) (*int, error) {
+	raw, ok := c.GetQuery(name)
+	if !ok {
+		return nil, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil || v < 0 {
+		return nil, &service.ValidationError{Field: name, Reason: "must be a non-negative integer"}
+	}
+	return &v, nil
+}
+
+// optionalBoolQuery reads a boolean query parameter, returning nil when it is
+// absent.
+func optionalBoolQuery(c *gin.Context, name string) (*bool, error) {
+	raw, ok := c.GetQuery(name)
+	if !ok {
+		return nil, nil
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, &service.ValidationError{Field: name, Reason: `must be "true" or "false"`}
+	}
+	return &v, nil
+}
+
+// floatQuery reads a query parameter between 0 and 1, returning def when it
+// is absent.
+func floatQuery(c *gin.Context, name string, def float64) (float64, error) {
+	raw, ok := c.GetQuery(name)
+	if !ok {
+		return def, nil
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil || v < 0 || v > 1 {
+		return 0, &service.ValidationError{Field: name, Reason: "must be a number between 0 and 1"}
+	}
+	return v, nil
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIVersion describes a version of the REST API. Versions differ in the
+// view countries are rendered in by default; old versions announce their
+// deprecation in every response.
+type APIVersion struct {
+	Name string
+	// DefaultView applies when a request sets neither view nor fields.
+	DefaultView string
+	// Deprecated is when the version was deprecated, zero for current
+	// versions.
+	Deprecated time.Time
+	// Sunset is when the version stops being served, zero if not planned.
+	Sunset time.Time
+	// Successor is the path prefix of the version replacing this one.
+	Successor string
+}
+
+// Versions of the REST API. V1 is the flat country model, which the
+// unversioned paths keep serving; V2 renders the full model by default.
+var (
+	V1 = APIVersion{
+		Name:        "v1",
+		DefaultView: ViewCompact,
+		Deprecated:  time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC),
+		Sunset:      time.Date(2027, time.May, 1, 0, 0, 0, 0, time.UTC),
+		Successor:   "/api/v2",
+	}
+	V2 = APIVersion{
+		Name:        "v2",
+		DefaultView: ViewFull,
+	}
+)
+
+// APIVersionKey is the gin context key holding the APIVersion of a request.
+const APIVersionKey = "apiVersion"
+
+// Version serves the routes below prefix as version v. Responses of a
+// deprecated version carry the Deprecation header of RFC 9745, the Sunset
+// header of RFC 8594 and a link to the same resource in the successor
+// version.
+func (handler Handler) Version(v APIVersion, prefix string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(APIVersionKey, v)
+		if !v.Deprecated.IsZero() {
+			c.Header("Deprecation", fmt.Sprintf("@%d", v.Deprecated.Unix()))
+			if !v.Sunset.IsZero() {
+				c.Header("Sunset", v.Sunset.UTC().Format(http.TimeFormat))
+			}
+			if v.Successor != "" {
+				rest, _ := strings.CutPrefix(c.Request.URL.Path, prefix)
+				c.Header("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, v.Successor, rest))
+			}
+		}
+		c.Next()
+	}
+}
+
+// apiVersion returns the version a request is served as. Routes outside a
+// versioned group are V1.
+func apiVersion(c *gin.Context) APIVersion {
+	if v, ok := c.Get(APIVersionKey); ok {
+		return v.(APIVersion)
+	}
+	return V1
+}
@@ -0,0 +1,90 @@
+package index
+
+import (
+	"sort"
+	"unicode/utf8"
+
+	"github.com/Prasang-money/searchSvc/models"
+)
+
+// Match is a country found by a fuzzy search. Name is the indexed name that
+// scored best, and Score its similarity to the query, from 0 to 1.
+type Match struct {
+	Country models.CountryMetadata
+	Name    string
+	Score   float64
+}
+
+// Fuzzy returns the countries with a name at least threshold similar to the
+// query, best first, at most limit of them. Similarity is one minus the edit
+// distance divided by the length of the longer string, so a single typo in
+// an eight letter name still scores 0.875.
+func (ix *Index) Fuzzy(query string, threshold float64, limit int) []Match {
+	q := Normalize(query)
+	if q == "" {
+		return nil
+	}
+
+	var matches []Match
+	for i, names := range ix.normalized {
+		best, bestName := 0.0, ""
+		for j, name := range names {
+			if name == "" {
+				continue
+			}
+			if score := Similarity(q, name); score > best {
+				best, bestName = score, ix.entries[i].Names[j]
+			}
+		}
+		if best >= threshold && best > 0 {
+			matches = append(matches, Match{Country: ix.entries[i].Country, Name: bestName, Score: best})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Country.Population > matches[j].Country.Population
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// Similarity scores how alike two strings are, from 0 (nothing in common)
+// to 1 (identical), based on their Levenshtein distance.
+func Similarity(a, b string) float64 {
+	longest := utf8.RuneCountInString(a)
+	if n := utf8.RuneCountInString(b); n > longest {
+		longest = n
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(Levenshtein(a, b))/float64(longest)
+}
+
+// Levenshtein returns the number of single rune insertions, deletions and
+// substitutions needed to turn a into b.
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,185 @@
+package index
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Prasang-money/searchSvc/models"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Entry is a country to be indexed. Names are the names fuzzy search and
+// autocomplete match on; Terms is every piece of text full-text search
+// matches on, weighted by how strongly a hit on it should rank.
+type Entry struct {
+	Country models.CountryMetadata
+	Names   []string
+	Terms   []Term
+}
+
+// Term is a piece of text a country can be found by.
+type Term struct {
+	Text   string
+	Weight float64
+}
+
+// Index is an immutable in-memory index over a set of countries. It is safe
+// for concurrent use; to change its contents build a new one.
+type Index struct {
+	entries []Entry
+	// normalized holds the normalized form of entries[i].Names, index for
+	// index, with "" for names that normalize to nothing
+	normalized [][]string
+	// prefixes is the sorted prefix index used by Suggest
+	prefixes []prefixKey
+	// postings is the inverted index used by Search
+	postings map[string][]posting
+
+	byName    map[string][]int
+	byCode    map[string]int
+	byCapital map[string][]int
+}
+
+// New builds an index over the given entries.
+func New(entries []Entry) *Index {
+	ix := &Index{
+		entries:    entries,
+		normalized: make([][]string, len(entries)),
+		byName:     make(map[string][]int),
+		byCode:     make(map[string]int),
+		byCapital:  make(map[string][]int),
+	}
+	for i, e := range entries {
+		ix.normalized[i] = make([]string, len(e.Names))
+		for j, name := range e.Names {
+			ix.normalized[i][j] = Normalize(name)
+		}
+
+		c := e.Country
+		for _, name := range append(append([]string{c.Name}, e.Names...), translationNames(c)...) {
+			if n := Normalize(name); n != "" {
+				ix.byName[n] = appendOnce(ix.byName[n], i)
+			}
+		}
+		for _, code := range []string{c.CCA2, c.CCA3, c.CCN3, c.CIOC} {
+			if code != "" {
+				ix.byCode[strings.ToUpper(code)] = i
+			}
+		}
+		for _, capital := range c.Capitals {
+			if n := Normalize(capital); n != "" {
+				ix.byCapital[n] = appendOnce(ix.byCapital[n], i)
+			}
+		}
+	}
+	ix.prefixes = buildPrefixKeys(ix.normalized)
+	ix.postings = buildPostings(entries)
+	return ix
+}
+
+// Len returns the number of indexed countries.
+func (ix *Index) Len() int {
+	return len(ix.entries)
+}
+
+// All returns every indexed country, in the order the index was built.
+func (ix *Index) All() []models.CountryMetadata {
+	all := make([]models.CountryMetadata, len(ix.entries))
+	for i, e := range ix.entries {
+		all[i] = e.Country
+	}
+	return all
+}
+
+// Each calls fn for every indexed country, in the order the index was built,
+// and stops at the first error fn returns.
+func (ix *Index) Each(fn func(models.CountryMetadata) error) error {
+	for _, e := range ix.entries {
+		if err := fn(e.Country); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ByName returns the country with the given common, official, alternative
+// or translated name, ignoring case and accents. A common name wins over
+// any other kind of name.
+func (ix *Index) ByName(name string) (models.CountryMetadata, bool) {
+	matches := ix.byName[Normalize(name)]
+	if len(matches) == 0 {
+		return models.CountryMetadata{}, false
+	}
+	for _, i := range matches {
+		if strings.EqualFold(ix.entries[i].Country.Name, name) {
+			return ix.entries[i].Country, true
+		}
+	}
+	return ix.entries[matches[0]].Country, true
+}
+
+// ByCode returns the country with the given cca2, cca3, ccn3 or cioc code,
+// ignoring case.
+func (ix *Index) ByCode(code string) (models.CountryMetadata, bool) {
+	i, ok := ix.byCode[strings.ToUpper(code)]
+	if !ok {
+		return models.CountryMetadata{}, false
+	}
+	return ix.entries[i].Country, true
+}
+
+// ByCapital returns the country with the given capital, ignoring case and
+// accents.
+func (ix *Index) ByCapital(capital string) (models.CountryMetadata, bool) {
+	matches := ix.byCapital[Normalize(capital)]
+	if len(matches) == 0 {
+		return models.CountryMetadata{}, false
+	}
+	return ix.entries[matches[0]].Country, true
+}
+
+func translationNames(c models.CountryMetadata) []string {
+	names := make([]string, 0, len(c.Translations))
+	for _, name := range c.Translations {
+		names = append(names, name)
+	}
+	return names
+}
+
+func appendOnce(list []int, i int) []int {
+	if len(list) > 0 && list[len(list)-1] == i {
+		return list
+	}
+	return append(list, i)
+}
+
+// Normalize lowercases s, strips diacritics and collapses whitespace, so
+// "Côte  d'Ivoire" and "cote d'ivoire" compare equal.
+func Normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(stripMarks(s))), " ")
+}
+
+func stripMarks(s string) string {
+	if isASCII(s) {
+		return s
+	}
+	// transformers keep state, so a fresh chain is needed per call
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	stripped, _, err := transform.String(t, s)
+	if err != nil {
+		return s
+	}
+	return stripped
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
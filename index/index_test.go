@@ -0,0 +1,250 @@
+package index
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Prasang-money/searchSvc/models"
+)
+
+func testIndex() *Index {
+	return New([]Entry{
+		{Country: models.CountryMetadata{Name: "Philippines", Population: 109581085}, Names: []string{"Philippines", "Republic of the Philippines", "PH"}},
+		{Country: models.CountryMetadata{Name: "Colombia", Population: 50882884}, Names: []string{"Colombia", "Republic of Colombia", "CO"}},
+		{Country: models.CountryMetadata{Name: "Côte d'Ivoire", Population: 26378275}, Names: []string{"Côte d'Ivoire", "Ivory Coast", "CI"}},
+		{Country: models.CountryMetadata{Name: "Germany", Population: 83240525}, Names: []string{"Germany", "Federal Republic of Germany", "DE"}},
+	})
+}
+
+// Test normalization of names
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Germany", "germany"},
+		{"Côte  d'Ivoire", "cote d'ivoire"},
+		{"  São Tomé and Príncipe ", "sao tome and principe"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.input); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+// Test edit distance
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"columbia", "colombia", 1},
+		{"phillipines", "philippines", 2},
+		{"kitten", "sitting", 3},
+		{"çà", "ca", 2},
+	}
+
+	for _, tt := range tests {
+		if got := Levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// Test fuzzy matching of misspelled names
+func TestFuzzy(t *testing.T) {
+	ix := testIndex()
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"Phillipines", "Philippines"},
+		{"Columbia", "Colombia"},
+		{"cote divoire", "Côte d'Ivoire"},
+		{"ivory cost", "Côte d'Ivoire"},
+		{"Germany", "Germany"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			matches := ix.Fuzzy(tt.query, 0.75, 1)
+			if len(matches) != 1 {
+				t.Fatalf("expected a match for %q, got none", tt.query)
+			}
+			if matches[0].Country.Name != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, matches[0].Country.Name)
+			}
+			if matches[0].Score < 0.75 || matches[0].Score > 1 {
+				t.Errorf("score %f out of range", matches[0].Score)
+			}
+		})
+	}
+}
+
+// Test threshold and ordering
+func TestFuzzy_ThresholdAndOrder(t *testing.T) {
+	ix := testIndex()
+
+	if matches := ix.Fuzzy("Atlantis", 0.75, 10); len(matches) != 0 {
+		t.Errorf("expected no matches, got %v", matches)
+	}
+
+	matches := ix.Fuzzy("Germany", 0, 10)
+	if len(matches) != ix.Len() {
+		t.Fatalf("expected every country with a zero threshold, got %d", len(matches))
+	}
+	if matches[0].Score != 1 || matches[0].Name != "Germany" {
+		t.Errorf("expected exact match first, got %+v", matches[0])
+	}
+	for i := 1; i < len(matches); i++ {
+		if matches[i].Score > matches[i-1].Score {
+			t.Errorf("matches not sorted by score: %v", matches)
+		}
+	}
+
+	if matches := ix.Fuzzy("Germany", 0, 2); len(matches) != 2 {
+		t.Errorf("expected limit to apply, got %d", len(matches))
+	}
+}
+
+// Test prefix suggestions
+func TestSuggest(t *testing.T) {
+	ix := New([]Entry{
+		{Country: models.CountryMetadata{Name: "Germany", Population: 83240525}, Names: []string{"Germany", "Federal Republic of Germany", "DE"}},
+		{Country: models.CountryMetadata{Name: "Georgia", Population: 3714000}, Names: []string{"Georgia", "", "GE"}},
+		{Country: models.CountryMetadata{Name: "South Korea", Population: 51780579}, Names: []string{"South Korea", "Republic of Korea", "KR"}},
+		{Country: models.CountryMetadata{Name: "North Korea", Population: 25778815}, Names: []string{"North Korea", "Democratic People's Republic of Korea", "KP"}},
+		{Country: models.CountryMetadata{Name: "Niger", Population: 24206636}, Names: []string{"Niger", "Republic of Niger", "NE"}},
+	})
+
+	tests := []struct {
+		prefix string
+		limit  int
+		want   []string
+	}{
+		{"ger", 10, []string{"Germany"}},
+		{"GE", 10, []string{"Germany", "Georgia"}},
+		{"kor", 10, []string{"South Korea", "North Korea"}},
+		{"republic of", 2, []string{"Germany", "South Korea"}},
+		{"xyz", 10, nil},
+		{"", 10, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			var got []string
+			for _, s := range ix.Suggest(tt.prefix, tt.limit) {
+				got = append(got, s.Country.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Suggest(%q) = %v, want %v", tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
+
+func searchIndex() *Index {
+	return New([]Entry{
+		{
+			Country: models.CountryMetadata{Name: "Guinea", Population: 13132792, CCA2: "GN", CCA3: "GIN", Capitals: []string{"Conakry"}},
+			Names:   []string{"Guinea", "Republic of Guinea"},
+			Terms:   []Term{{"Guinea", 10}, {"Republic of Guinea", 6}, {"Conakry", 4}, {"French", 2}, {"GNF", 2}, {"Guinean franc", 2}},
+		},
+		{
+			Country: models.CountryMetadata{Name: "Papua New Guinea", Population: 8947027, CCA2: "PG", CCA3: "PNG", Capitals: []string{"Port Moresby"}},
+			Names:   []string{"Papua New Guinea", "Independent State of Papua New Guinea"},
+			Terms:   []Term{{"Papua New Guinea", 10}, {"Independent State of Papua New Guinea", 6}, {"Port Moresby", 4}, {"English", 2}, {"PGK", 2}, {"Papua New Guinean kina", 2}},
+		},
+		{
+			Country: models.CountryMetadata{Name: "Switzerland", Population: 8654622, CCA2: "CH", CCA3: "CHE", Capitals: []string{"Bern"}, Translations: map[string]string{"deu": "Schweiz"}},
+			Names:   []string{"Switzerland", "Swiss Confederation", "Schweiz"},
+			Terms:   []Term{{"Switzerland", 10}, {"Swiss Confederation", 6}, {"Schweiz", 4}, {"Bern", 4}, {"French", 2}, {"German", 2}, {"CHF", 2}, {"Swiss franc", 2}},
+		},
+		{
+			Country: models.CountryMetadata{Name: "France", Population: 67391582, CCA2: "FR", CCA3: "FRA", Capitals: []string{"Paris"}},
+			Names:   []string{"France", "French Republic"},
+			Terms:   []Term{{"France", 10}, {"French Republic", 6}, {"Paris", 4}, {"French", 2}, {"EUR", 2}, {"Euro", 2}},
+		},
+	})
+}
+
+func TestSearch(t *testing.T) {
+	ix := searchIndex()
+
+	tests := []struct {
+		query string
+		limit int
+		want  []string
+	}{
+		{"guinea", 10, []string{"Guinea", "Papua New Guinea"}},
+		{"new guinea", 10, []string{"Papua New Guinea"}},
+		{"french", 10, []string{"France", "Guinea", "Switzerland"}},
+		{"french", 1, []string{"France"}},
+		{"franc", 10, []string{"Guinea", "Switzerland"}},
+		{"CHF", 10, []string{"Switzerland"}},
+		{"port moresby", 10, []string{"Papua New Guinea"}},
+		{"schweiz", 10, []string{"Switzerland"}},
+		{"french xyz", 10, nil},
+		{"", 10, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var got []string
+			for _, m := range ix.Search(tt.query, tt.limit) {
+				got = append(got, m.Country.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+// Test exact lookups by name, code and capital
+func TestLookups(t *testing.T) {
+	ix := searchIndex()
+
+	if c, ok := ix.ByName("SCHWEIZ"); !ok || c.Name != "Switzerland" {
+		t.Errorf("ByName(SCHWEIZ) = %v, %v", c.Name, ok)
+	}
+	if c, ok := ix.ByName("republic of guinea"); !ok || c.Name != "Guinea" {
+		t.Errorf("ByName(republic of guinea) = %v, %v", c.Name, ok)
+	}
+	if _, ok := ix.ByName("Guin"); ok {
+		t.Errorf("expected no match on a partial name")
+	}
+	if c, ok := ix.ByCode("png"); !ok || c.Name != "Papua New Guinea" {
+		t.Errorf("ByCode(png) = %v, %v", c.Name, ok)
+	}
+	if c, ok := ix.ByCapital("paris"); !ok || c.Name != "France" {
+		t.Errorf("ByCapital(paris) = %v, %v", c.Name, ok)
+	}
+	if ix.Len() != 4 || len(ix.All()) != 4 {
+		t.Errorf("expected 4 countries, got %d", ix.Len())
+	}
+}
+
+func BenchmarkSuggest(b *testing.B) {
+	var entries []Entry
+	for i := 0; i < 250; i++ {
+		name := fmt.Sprintf("Country %03d", i)
+		entries = append(entries, Entry{
+			Country: models.CountryMetadata{Name: name, Population: i},
+			Names:   []string{name, "Republic of " + name, fmt.Sprintf("C%d", i)},
+		})
+	}
+	ix := New(entries)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ix.Suggest("country 1", 10)
+	}
+}
@@ -0,0 +1,91 @@
+package index
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// posting records that a token occurs in a term of an entry. Weight is the
+// highest weight among the entry's terms containing the token.
+type posting struct {
+	entry  int
+	weight float64
+}
+
+func buildPostings(entries []Entry) map[string][]posting {
+	postings := make(map[string][]posting)
+	for i, e := range entries {
+		best := make(map[string]float64)
+		for _, term := range e.Terms {
+			for _, token := range Tokenize(term.Text) {
+				if term.Weight > best[token] {
+					best[token] = term.Weight
+				}
+			}
+		}
+		for token, weight := range best {
+			postings[token] = append(postings[token], posting{entry: i, weight: weight})
+		}
+	}
+	return postings
+}
+
+// Tokenize splits normalized text into words of letters and digits.
+func Tokenize(s string) []string {
+	return strings.FieldsFunc(Normalize(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Search runs a full-text query and returns the matching countries, best
+// first, at most limit of them. Every word of the query must occur in some
+// term of a country. A country scores the sum, over the query words, of the
+// weight of the strongest term containing the word, plus the weight of any
+// term the whole query is equal to, so "guinea" ranks Guinea above Papua New
+// Guinea. Ties go to the more populous country.
+func (ix *Index) Search(query string, limit int) []Match {
+	tokens := Tokenize(query)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	scores := make(map[int]float64)
+	for i, token := range tokens {
+		next := make(map[int]float64)
+		for _, p := range ix.postings[token] {
+			if prev, ok := scores[p.entry]; ok || i == 0 {
+				next[p.entry] = prev + p.weight
+			}
+		}
+		scores = next
+	}
+
+	phrase := strings.Join(tokens, " ")
+	matches := make([]Match, 0, len(scores))
+	for entry, score := range scores {
+		e := ix.entries[entry]
+		name := e.Country.Name
+		best := 0.0
+		for _, term := range e.Terms {
+			if term.Weight > best && strings.Join(Tokenize(term.Text), " ") == phrase {
+				best, name = term.Weight, term.Text
+			}
+		}
+		matches = append(matches, Match{Country: e.Country, Name: name, Score: score + best})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		if matches[i].Country.Population != matches[j].Country.Population {
+			return matches[i].Country.Population > matches[j].Country.Population
+		}
+		return matches[i].Country.Name < matches[j].Country.Name
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
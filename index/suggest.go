@@ -0,0 +1,86 @@
+package index
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Prasang-money/searchSvc/models"
+)
+
+// prefixKey is one searchable key of the sorted prefix index: a normalized
+// name, or the tail of one starting at a word boundary, so "kor" finds
+// "South Korea".
+type prefixKey struct {
+	key   string
+	entry int
+	name  int
+}
+
+// Suggestion is a country whose name starts with a prefix. Name is the
+// indexed name that matched.
+type Suggestion struct {
+	Country models.CountryMetadata
+	Name    string
+}
+
+func buildPrefixKeys(normalized [][]string) []prefixKey {
+	var keys []prefixKey
+	for i, names := range normalized {
+		for j, name := range names {
+			if name == "" {
+				continue
+			}
+			keys = append(keys, prefixKey{key: name, entry: i, name: j})
+			for k := 0; k < len(name); k++ {
+				if name[k] == ' ' {
+					keys = append(keys, prefixKey{key: name[k+1:], entry: i, name: j})
+				}
+			}
+		}
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		return keys[a].key < keys[b].key
+	})
+	return keys
+}
+
+// Suggest returns up to limit countries with a name, or a word of a name,
+// starting with prefix, most populous first. It binary searches the sorted
+// prefix index, so it is cheap enough to call on every keystroke.
+func (ix *Index) Suggest(prefix string, limit int) []Suggestion {
+	p := Normalize(prefix)
+	if p == "" {
+		return nil
+	}
+
+	start := sort.Search(len(ix.prefixes), func(i int) bool {
+		return ix.prefixes[i].key >= p
+	})
+
+	// collect each matching country once, with the first name that matched,
+	// and rank by index so sorting does not copy whole countries around
+	seen := make(map[int]bool)
+	var matches []prefixKey
+	for i := start; i < len(ix.prefixes) && strings.HasPrefix(ix.prefixes[i].key, p); i++ {
+		if k := ix.prefixes[i]; !seen[k.entry] {
+			seen[k.entry] = true
+			matches = append(matches, k)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return ix.entries[matches[i].entry].Country.Population > ix.entries[matches[j].entry].Country.Population
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	suggestions := make([]Suggestion, len(matches))
+	for i, k := range matches {
+		suggestions[i] = Suggestion{
+			Country: ix.entries[k.entry].Country,
+			Name:    ix.entries[k.entry].Names[k.name],
+		}
+	}
+	return suggestions
+}
@@ -0,0 +1,145 @@
+// Package servicetest helps test the packages built on the service: it
+// mocks the service and holds the countries tests look up. It is internal
+// so the mocking library it uses stays out of reach of importers of the
+// module; only test files import it.
+package servicetest
+
+import (
+	"github.com/Prasang-money/searchSvc/models"
+	"github.com/Prasang-money/searchSvc/service"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock is a mock implementation of service.ServiceInterface and
+// service.Admin.
+type Mock struct {
+	mock.Mock
+	// Freshness is reported by successful lookups that ask for it
+	Freshness service.Freshness
+}
+
+var (
+	_ service.ServiceInterface = (*Mock)(nil)
+	_ service.Admin            = (*Mock)(nil)
+)
+
+// lookup answers a lookup by a single name or code.
+func (m *Mock) lookup(args mock.Arguments, opts []service.Option) (*models.CountryMetadata, error) {
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	if f := service.FreshnessOf(opts...); f != nil {
+		*f = m.Freshness
+	}
+	return args.Get(0).(*models.CountryMetadata), args.Error(1)
+}
+
+func (m *Mock) SearchCountries(name string, opts ...service.Option) (*models.CountryMetadata, error) {
+	return m.lookup(m.Called(name), opts)
+}
+
+func (m *Mock) SearchByCode(code string, opts ...service.Option) (*models.CountryMetadata, error) {
+	return m.lookup(m.Called(code), opts)
+}
+
+func (m *Mock) SearchByCapital(capital string, opts ...service.Option) (*models.CountryMetadata, error) {
+	return m.lookup(m.Called(capital), opts)
+}
+
+func (m *Mock) SearchByCurrency(currency string, opts ...service.Option) ([]models.CurrencyMatch, error) {
+	args := m.Called(currency)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.CurrencyMatch), args.Error(1)
+}
+
+func (m *Mock) FuzzySearch(query string, threshold float64, limit int) ([]models.FuzzyMatch, error) {
+	args := m.Called(query, threshold, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.FuzzyMatch), args.Error(1)
+}
+
+func (m *Mock) ListCountries(q service.ListQuery) (*service.Page, error) {
+	args := m.Called(q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.Page), args.Error(1)
+}
+
+func (m *Mock) BatchLookup(items []models.BatchItem, atomic bool, opts ...service.Option) ([]service.BatchResult, error) {
+	args := m.Called(items, atomic)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]service.BatchResult), args.Error(1)
+}
+
+// ExportCountries passes fn the countries it was set up to return, then
+// returns the error it was set up with.
+func (m *Mock) ExportCountries(fn func(models.CountryMetadata) error) error {
+	args := m.Called()
+	if countries, ok := args.Get(0).([]models.CountryMetadata); ok {
+		for _, country := range countries {
+			if err := fn(country); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *Mock) Suggest(prefix string, limit int) ([]models.Suggestion, error) {
+	args := m.Called(prefix, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Suggestion), args.Error(1)
+}
+
+func (m *Mock) CacheStats() models.CacheStats {
+	return m.Called().Get(0).(models.CacheStats)
+}
+
+func (m *Mock) PurgeCache() int {
+	return m.Called().Int(0)
+}
+
+func (m *Mock) WarmCache() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+// Countries looked up by tests. Germany has every field set, the others
+// only those needed to link them to it.
+var (
+	Germany = models.CountryMetadata{
+		Name:         "Germany",
+		Population:   83240525,
+		Capital:      "Berlin",
+		Currency:     "€",
+		CCA2:         "DE",
+		CCA3:         "DEU",
+		CCN3:         "276",
+		CIOC:         "GER",
+		Capitals:     []string{"Berlin"},
+		Currencies:   []models.Currency{{Code: "EUR", Name: "Euro", Symbol: "€"}},
+		Region:       "Europe",
+		Subregion:    "Western Europe",
+		Languages:    []models.Language{{Code: "deu", Name: "German"}},
+		Borders:      []string{"AUT", "CHE", "FRA"},
+		LatLng:       []float64{51, 9},
+		Area:         357114,
+		Timezones:    []string{"UTC+01:00"},
+		Flags:        &models.Flags{PNG: "https://flagcdn.com/w320/de.png", SVG: "https://flagcdn.com/de.svg"},
+		CallingCodes: []string{"+49"},
+		TLD:          []string{".de"},
+		Translations: map[string]string{"deu": "Deutschland", "fra": "Allemagne"},
+	}
+	Austria     = models.CountryMetadata{Name: "Austria", Capital: "Vienna", CCA2: "AT", CCA3: "AUT", Borders: []string{"DEU", "CHE"}, Landlocked: true}
+	Switzerland = models.CountryMetadata{Name: "Switzerland", Capital: "Bern", CCA2: "CH", CCA3: "CHE", Borders: []string{"AUT", "DEU", "FRA"}, Landlocked: true}
+	France      = models.CountryMetadata{Name: "France", Capital: "Paris", CCA2: "FR", CCA3: "FRA", Borders: []string{"CHE", "DEU"}}
+)
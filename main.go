@@ -3,16 +3,49 @@ package main
 import (
 	"context"
 	"log"
+	"net"
 	"net/http"
+	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/Prasang-money/searchSvc/cache"
 	"github.com/Prasang-money/searchSvc/route"
+	"github.com/Prasang-money/searchSvc/rpc"
+	"github.com/Prasang-money/searchSvc/service"
 )
 
+// indexRefreshInterval is how often the local country index is reloaded
+// from the upstream API. Country data changes rarely.
+const indexRefreshInterval = 6 * time.Hour
+
+// cacheTTL is how long looked up countries are cached, in line with the
+// refresh interval of the local index.
+const cacheTTL = indexRefreshInterval
+
+// cacheCapacity bounds the entries of the country cache. A country is
+// cached under its name, codes and capitals, about six keys, so warming the
+// cache with all 250 countries evicts none.
+const cacheCapacity = 2000
+
+// adminTokenEnv names the variable holding the token of the admin
+// endpoints. They are not served when it is unset.
+const adminTokenEnv = "SEARCHSVC_ADMIN_TOKEN"
+
+// grpcAddr is the address of the gRPC API, served next to the REST API.
+const grpcAddr = ":9090"
+
 func main() {
-	router := route.GetRoute()
+	// Setting up channel to listen for interrupt or terminate signal from OS.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	cache := cache.NewCacheWithTTL(cacheCapacity, cacheTTL)
+	service := service.NewService(cache)
+	service.StartIndexRefresh(ctx, indexRefreshInterval)
+
+	router := route.GetRoute(service, route.WithAdminToken(os.Getenv(adminTokenEnv)))
 	server := &http.Server{
 		Addr:    ":8080",
 		Handler: router,
@@ -26,9 +59,17 @@ func main() {
 
 	}()
 
-	// Setting up channel to listen for interrupt or terminate signal from OS.
-	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
+	grpcServer := rpc.NewGRPCServer(service)
+	listener, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		log.Fatalf("listening on %s: %v", grpcAddr, err)
+	}
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil {
+			panic(err)
+		}
+	}()
+
 	// wait for interrupt signal
 	<-ctx.Done()
 	stop()
@@ -39,8 +80,20 @@ func main() {
 	ctxShutDown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// GracefulStop waits for streams, so it is bounded by the same deadline
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+
 	if err := server.Shutdown(ctxShutDown); err != nil {
 		log.Fatalf("server forced to shutdown: %v", err)
 	}
+	select {
+	case <-grpcStopped:
+	case <-ctxShutDown.Done():
+		grpcServer.Stop()
+	}
 	log.Println("server closed")
 }
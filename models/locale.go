@@ -0,0 +1,35 @@
+package models
+
+// DefaultLanguage is the language of CountryMetadata.Name.
+const DefaultLanguage = "en"
+
+// TranslationKeys maps the ISO 639-1 codes of the languages upstream
+// translates country names into to the ISO 639 keys it uses for them.
+var TranslationKeys = map[string]string{
+	"ar": "ara",
+	"br": "bre",
+	"cs": "ces",
+	"cy": "cym",
+	"de": "deu",
+	"et": "est",
+	"fa": "per",
+	"fi": "fin",
+	"fr": "fra",
+	"hr": "hrv",
+	"hu": "hun",
+	"id": "ind",
+	"it": "ita",
+	"ja": "jpn",
+	"ko": "kor",
+	"nl": "nld",
+	"pl": "pol",
+	"pt": "por",
+	"ru": "rus",
+	"sk": "slk",
+	"es": "spa",
+	"sr": "srp",
+	"sv": "swe",
+	"tr": "tur",
+	"ur": "urd",
+	"zh": "zho",
+}
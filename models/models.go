@@ -1,16 +1,97 @@
 package models
 
-//
+import (
+	"reflect"
+	"strings"
+)
 
 type Country struct {
-	Name       Name                  `json:"name"`
-	Population int                   `json:"population"`
-	Capital    []string              `json:"capital"`
-	Currencies map[string]Currencies `json:"currencies"`
+	Name         Name                  `json:"name"`
+	CCA2         string                `json:"cca2"`
+	CCA3         string                `json:"cca3"`
+	CCN3         string                `json:"ccn3"`
+	CIOC         string                `json:"cioc"`
+	AltSpellings []string              `json:"altSpellings"`
+	Population   int                   `json:"population"`
+	Capital      []string              `json:"capital"`
+	Currencies   map[string]Currencies `json:"currencies"`
+	Region       string                `json:"region"`
+	Subregion    string                `json:"subregion"`
+	Languages    map[string]string     `json:"languages"`
+	Borders      []string              `json:"borders"`
+	LatLng       []float64             `json:"latlng"`
+	Area         float64               `json:"area"`
+	Landlocked   bool                  `json:"landlocked"`
+	Timezones    []string              `json:"timezones"`
+	Flags        Flags                 `json:"flags"`
+	IDD          IDD                   `json:"idd"`
+	TLD          []string              `json:"tld"`
+
+	// Translations are keyed by ISO 639-3 language code.
+	Translations map[string]Translation `json:"translations"`
+}
+
+// Codes returns the ISO 3166 alpha-2, alpha-3 and numeric codes and the IOC
+// code of the country, skipping the ones upstream does not define.
+func (c Country) Codes() []string {
+	var codes []string
+	for _, code := range []string{c.CCA2, c.CCA3, c.CCN3, c.CIOC} {
+		if code != "" {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// Names returns every name of the country: common, official, native and
+// translated.
+func (c Country) Names() []string {
+	names := []string{c.Name.Common, c.Name.Official}
+	for _, n := range c.Name.NativeName {
+		names = append(names, n.Common, n.Official)
+	}
+	for _, t := range c.Translations {
+		names = append(names, t.Common, t.Official)
+	}
+	return names
 }
 
 type Name struct {
-	Common string `json:"common"`
+	Common     string                 `json:"common"`
+	Official   string                 `json:"official,omitempty"`
+	NativeName map[string]Translation `json:"nativeName,omitempty"`
+}
+
+// Translation is the name of a country in a given language.
+type Translation struct {
+	Common   string `json:"common"`
+	Official string `json:"official"`
+}
+
+// Flags holds the URLs of a country's flag images.
+type Flags struct {
+	PNG string `json:"png,omitempty"`
+	SVG string `json:"svg,omitempty"`
+	Alt string `json:"alt,omitempty"`
+}
+
+// IDD is the international direct dialing prefix of a country, split into a
+// root such as "+4" and one or more suffixes such as "9".
+type IDD struct {
+	Root     string   `json:"root"`
+	Suffixes []string `json:"suffixes"`
+}
+
+// CallingCodes returns the full calling codes of the country. Countries that
+// share a root across many area codes, like the "+1" zone, report the root.
+func (i IDD) CallingCodes() []string {
+	if i.Root == "" {
+		return nil
+	}
+	if len(i.Suffixes) != 1 {
+		return []string{i.Root}
+	}
+	return []string{i.Root + i.Suffixes[0]}
 }
 
 type Currencies struct {
@@ -18,9 +99,190 @@ type Currencies struct {
 	Symbol string `json:"symbol"`
 }
 
+// Currency is an ISO 4217 currency as used by a country.
+type Currency struct {
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Symbol string `json:"symbol"`
+}
+
+// FuzzyMatch is a country returned by a fuzzy search, together with the name
+// that matched and how closely, from 0 to 1.
+type FuzzyMatch struct {
+	CountryMetadata
+	MatchedName string  `json:"matchedName"`
+	Score       float64 `json:"score"`
+}
+
+// SearchHit is a country returned by a list query with its relevance score,
+// which is only set when the query has search terms. Scores only compare
+// hits of the same query.
+type SearchHit struct {
+	CountryMetadata
+	Score float64 `json:"score,omitempty"`
+}
+
+// Suggestion is an autocomplete result: the common name of a country and
+// the name that started with the typed prefix.
+type Suggestion struct {
+	Name        string `json:"name"`
+	MatchedName string `json:"matchedName"`
+	Population  int    `json:"population"`
+}
+
+// BatchItem identifies a country of a batch lookup, by name or by code.
+type BatchItem struct {
+	Name string `json:"name,omitempty"`
+	Code string `json:"code,omitempty"`
+}
+
+// Language is a language spoken in a country, keyed by its ISO 639-3 code.
+type Language struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// CurrencyMatch is a country returned by a currency search, together with the
+// currencies of that country that matched the query.
+type CurrencyMatch struct {
+	CountryMetadata
+	MatchedCurrencies []Currency `json:"matchedCurrencies"`
+}
+
+// CountryMetadata describes a country. Capital and Currency are the flat,
+// backwards compatible view of the first capital and of the currency with
+// the lowest code. Every other field is only serialized in the full view or
+// when requested through a field projection.
 type CountryMetadata struct {
-	Name       string `json:"name"`
-	Population int    `json:"population"`
-	Capital    string `json:"capital"`
-	Currency   string `json:"currency"`
+	Name         string     `json:"name"`
+	Population   int        `json:"population"`
+	Capital      string     `json:"capital"`
+	Currency     string     `json:"currency"`
+	CCA2         string     `json:"cca2,omitempty"`
+	CCA3         string     `json:"cca3,omitempty"`
+	CCN3         string     `json:"ccn3,omitempty"`
+	CIOC         string     `json:"cioc,omitempty"`
+	Capitals     []string   `json:"capitals,omitempty"`
+	Currencies   []Currency `json:"currencies,omitempty"`
+	Region       string     `json:"region,omitempty"`
+	Subregion    string     `json:"subregion,omitempty"`
+	Languages    []Language `json:"languages,omitempty"`
+	Borders      []string   `json:"borders,omitempty"`
+	LatLng       []float64  `json:"latlng,omitempty"`
+	Area         float64    `json:"area,omitempty"`
+	Landlocked   bool       `json:"landlocked,omitempty"`
+	Timezones    []string   `json:"timezones,omitempty"`
+	Flags        *Flags     `json:"flags,omitempty"`
+	CallingCodes []string   `json:"callingCodes,omitempty"`
+	TLD          []string   `json:"tld,omitempty"`
+
+	// Translations maps ISO 639-3 language codes to the common name of the
+	// country in that language.
+	Translations map[string]string `json:"translations,omitempty"`
+}
+
+// Compact returns the flat view of the country, without the lists.
+func (m CountryMetadata) Compact() CountryMetadata {
+	return CountryMetadata{
+		Name:       m.Name,
+		Population: m.Population,
+		Capital:    m.Capital,
+		Currency:   m.Currency,
+	}
+}
+
+// LocalizedName returns the common name of the country in the given ISO
+// 639-1 language, falling back to the English name.
+func (m CountryMetadata) LocalizedName(lang string) string {
+	if name := m.Translations[TranslationKeys[lang]]; name != "" {
+		return name
+	}
+	return m.Name
+}
+
+// Project returns only the requested fields of the country, keyed by their
+// JSON names. Unknown names are ignored.
+func (m CountryMetadata) Project(fields []string) map[string]any {
+	wanted := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		wanted[f] = true
+	}
+
+	result := make(map[string]any, len(fields))
+	v := reflect.ValueOf(m)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := jsonName(t.Field(i))
+		if wanted[name] {
+			result[name] = v.Field(i).Interface()
+		}
+	}
+	return result
+}
+
+// MetadataFields returns the JSON names of the fields of CountryMetadata, in
+// declaration order.
+func MetadataFields() []string {
+	t := reflect.TypeOf(CountryMetadata{})
+	fields := make([]string, t.NumField())
+	for i := range fields {
+		fields[i] = jsonName(t.Field(i))
+	}
+	return fields
+}
+
+// jsonName returns the name a struct field is serialized under.
+func jsonName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+// Problem is an RFC 7807 problem details document. Every failed request is
+// answered with one, served as application/problem+json.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code"`
+	RequestID string `json:"requestId,omitempty"`
+
+	// InvalidParams lists the rejected parameters of a 400 response.
+	InvalidParams []InvalidParam `json:"invalidParams,omitempty"`
+
+	// Suggestions lists close matches for a query that found nothing.
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// Codes of the errors of the service, reported in the Code of a Problem and
+// the extensions of GraphQL errors.
+const (
+	CodeNotFound            = "not_found"
+	CodeInvalidInput        = "invalid_input"
+	CodeUpstreamUnavailable = "upstream_unavailable"
+	CodeUpstreamBadResponse = "upstream_bad_response"
+	CodeTimeout             = "upstream_timeout"
+	CodeInternal            = "internal_error"
+)
+
+// InvalidParam explains why a request parameter was rejected.
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// CacheStats describes the country cache of a service: its size, and its
+// hits, misses, evictions and expirations since the service started.
+type CacheStats struct {
+	Size        int    `json:"size"`
+	Capacity    int    `json:"capacity"`
+	TTLSeconds  int64  `json:"ttlSeconds"`
+	Hits        uint64 `json:"hits"`
+	Misses      uint64 `json:"misses"`
+	Evictions   uint64 `json:"evictions"`
+	Expirations uint64 `json:"expirations"`
 }
@@ -0,0 +1,80 @@
+package openapi
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+// ui holds the page and initializer of the docs, pointing Swagger UI at the
+// document. The assets of Swagger UI itself are embedded by swaggerFiles.
+//
+//go:embed ui
+var ui embed.FS
+
+// Handler serves the document and the docs UI.
+type Handler struct {
+	document []byte
+	assets   fs.FS
+}
+
+// NewHandler serves the document of the service with the country endpoints
+// under each of groups. It panics if the document cannot be encoded.
+func NewHandler(groups []Group) *Handler {
+	document, err := json.MarshalIndent(Build(groups), "", "  ")
+	if err != nil {
+		panic("openapi: encoding document: " + err.Error())
+	}
+	page, err := fs.Sub(ui, "ui")
+	if err != nil {
+		panic("openapi: " + err.Error())
+	}
+	return &Handler{document: document, assets: layers{page, swaggerFiles.FS}}
+}
+
+// Spec serves the OpenAPI document.
+func (handler *Handler) Spec() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", handler.document)
+	}
+}
+
+// UI serves the docs from a route ending in the *filepath wildcard. Files
+// that do not exist are answered by notFound.
+func (handler *Handler) UI(notFound gin.HandlerFunc) gin.HandlerFunc {
+	files := http.FileServerFS(handler.assets)
+	return func(c *gin.Context) {
+		name := strings.TrimPrefix(c.Param("filepath"), "/")
+		if name == "" {
+			name = "index.html"
+		}
+		if _, err := fs.Stat(handler.assets, name); err != nil {
+			notFound(c)
+			return
+		}
+
+		// the file server resolves the wildcard part of the path only
+		req := c.Request.Clone(c.Request.Context())
+		req.URL.Path = c.Param("filepath")
+		files.ServeHTTP(c.Writer, req)
+	}
+}
+
+// layers opens a file from the first file system that has it.
+type layers []fs.FS
+
+func (l layers) Open(name string) (fs.File, error) {
+	var err error
+	for _, fsys := range l {
+		var f fs.File
+		if f, err = fsys.Open(name); err == nil {
+			return f, nil
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,67 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/Prasang-money/searchSvc/handler"
+	"github.com/Prasang-money/searchSvc/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemas(t *testing.T) {
+	s := newSchemas(models.CountryMetadata{})
+
+	assert.Equal(t, Ref("FuzzyMatch"), s.of(models.FuzzyMatch{}))
+	fuzzy := s.components["FuzzyMatch"]
+	// embedded fields are inlined, and the projected ones are optional
+	assert.Contains(t, fuzzy.Properties, "name")
+	assert.Contains(t, fuzzy.Properties, "currencies")
+	assert.Equal(t, []string{"matchedName", "score"}, fuzzy.Required)
+	assert.Equal(t, &Schema{Type: "number", Format: "double"}, fuzzy.Properties["score"])
+
+	assert.Equal(t, Ref("CountryMetadata"), s.of(models.CountryMetadata{}))
+	country := s.components["CountryMetadata"]
+	assert.Empty(t, country.Required)
+	assert.Equal(t, &Schema{Type: "array", Items: Ref("Currency")}, country.Properties["currencies"])
+	assert.Equal(t, Ref("Flags"), country.Properties["flags"])
+	assert.Equal(t, &Schema{Type: "object", AdditionalProperties: &Schema{Type: "string"}}, country.Properties["translations"])
+
+	s.of(models.Problem{})
+	assert.Equal(t, []string{"type", "title", "status", "code"}, s.components["Problem"].Required)
+}
+
+func TestBuild_Versions(t *testing.T) {
+	doc := Build([]Group{
+		{Prefix: "/api/v2", Version: handler.V2},
+		{Prefix: "/api/v1", Version: handler.V1},
+		{Prefix: "/api", Version: handler.V1},
+	})
+
+	tests := []struct {
+		path           string
+		wantID         string
+		wantView       any
+		wantDeprecated bool
+	}{
+		{"/api/v2/countries/search", "v2SearchCountries", handler.ViewFull, false},
+		{"/api/v1/countries/search", "v1SearchCountries", handler.ViewCompact, true},
+		{"/api/countries/search", "searchCountries", handler.ViewCompact, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			op := doc.Paths[tt.path].Get
+			if !assert.NotNil(t, op) {
+				return
+			}
+			assert.Equal(t, tt.wantID, op.OperationID)
+			assert.Equal(t, tt.wantDeprecated, op.Deprecated)
+			for _, p := range op.Parameters {
+				if p.Name == "view" {
+					assert.Equal(t, tt.wantView, p.Schema.Default)
+				}
+			}
+			_, hasSunset := op.Responses["200"].Headers["Sunset"]
+			assert.Equal(t, tt.wantDeprecated, hasSunset)
+		})
+	}
+}
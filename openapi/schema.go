@@ -0,0 +1,99 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+)
+
+// schemas generates the component schemas of Go types from their fields and
+// JSON tags, following the rules of encoding/json, so the document cannot
+// drift from the models.
+type schemas struct {
+	components map[string]*Schema
+	// projected are the types whose fields the fields parameter may leave
+	// out of a response, so none of them is required.
+	projected map[reflect.Type]bool
+}
+
+func newSchemas(projected ...any) *schemas {
+	s := &schemas{
+		components: make(map[string]*Schema),
+		projected:  make(map[reflect.Type]bool),
+	}
+	for _, v := range projected {
+		s.projected[reflect.TypeOf(v)] = true
+	}
+	return s
+}
+
+// of returns the schema of the type of v. Named structs are added to the
+// components and referred to.
+func (s *schemas) of(v any) *Schema {
+	return s.typeSchema(reflect.TypeOf(v))
+}
+
+func (s *schemas) typeSchema(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return s.typeSchema(t.Elem())
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: s.typeSchema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.typeSchema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		if _, ok := s.components[t.Name()]; !ok {
+			// registered first so recursive types refer to themselves
+			s.components[t.Name()] = &Schema{}
+			*s.components[t.Name()] = *s.object(t)
+		}
+		return Ref(t.Name())
+	default:
+		// any value
+		return &Schema{}
+	}
+}
+
+// object returns the schema of a struct. Fields without omitempty are
+// always marshaled, so they are required.
+func (s *schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	s.addFields(schema, t, s.projected[t])
+	return schema
+}
+
+func (s *schemas) addFields(schema *Schema, t reflect.Type, optional bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		// encoding/json inlines the fields of untagged embedded structs
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			s.addFields(schema, f.Type, optional || s.projected[f.Type])
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		schema.Properties[name] = s.typeSchema(f.Type)
+		if !optional && !strings.Contains(opts, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
@@ -0,0 +1,610 @@
+// Package openapi describes the HTTP API of the service as an OpenAPI 3
+// document and serves it together with a Swagger UI.
+//
+// Schemas are generated from the models, and the country endpoints are
+// described once and expanded under every version prefix, so the document
+// follows the router. The route package tests that it does.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Prasang-money/searchSvc/handler"
+	"github.com/Prasang-money/searchSvc/models"
+	"github.com/Prasang-money/searchSvc/service"
+)
+
+// Title and version of the described API.
+const (
+	Title   = "Country Search Service"
+	Version = "2.0.0"
+)
+
+// Group is a path prefix the country endpoints are served under, as one
+// version of the API.
+type Group struct {
+	Prefix  string
+	Version handler.APIVersion
+}
+
+// Unversioned tags the paths that predate versioning.
+const Unversioned = "unversioned"
+
+// name tags the operations of the group with its version.
+func (g Group) name() string {
+	if strings.HasSuffix(g.Prefix, "/"+g.Version.Name) {
+		return g.Version.Name
+	}
+	return Unversioned
+}
+
+const description = `Looks up countries by name, code, capital or currency, and lists, searches and exports them.
+
+Responses are JSON by default. The Accept header negotiates XML, CSV, YAML or protobuf instead, and Accept-Encoding negotiates zstd, br or gzip compression. Errors are RFC 7807 problem documents.`
+
+// schemaDescriptions document the generated component schemas.
+var schemaDescriptions = map[string]string{
+	"CountryMetadata": "A country. The compact view only has name, population, capital and currency; the full view has every field upstream defines; the fields parameter selects exactly the listed ones.",
+	"CurrencyMatch":   "A country with the currencies that matched a currency search.",
+	"FuzzyMatch":      "A country with the name that matched a fuzzy search and how closely, from 0 to 1.",
+	"SearchHit":       "A country of a list query, with its relevance score when the query has search terms.",
+	"Suggestion":      "An autocomplete result.",
+	"BatchItem":       "A country of a batch, by name or by code.",
+	"Problem":         "An RFC 7807 problem document.",
+}
+
+// Build describes the service, with the country endpoints served under each
+// of groups.
+func Build(groups []Group) *Document {
+	s := newSchemas(models.CountryMetadata{})
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info:    Info{Title: Title, Description: description, Version: Version},
+		Paths:   make(map[string]*PathItem),
+	}
+
+	for _, g := range groups {
+		doc.Tags = append(doc.Tags, Tag{Name: g.name(), Description: groupDescription(g)})
+		for _, e := range countryEndpoints(s, g.Version) {
+			op := e.op
+			op.Tags = []string{g.name()}
+			if g.name() != Unversioned {
+				op.OperationID = g.name() + strings.ToUpper(op.OperationID[:1]) + op.OperationID[1:]
+			}
+			if !g.Version.Deprecated.IsZero() {
+				op.Deprecated = true
+				addHeaders(op, deprecationHeaders(g.Version))
+			}
+			addPath(doc, e.method, g.Prefix+e.path, op)
+		}
+	}
+	doc.Tags = append(doc.Tags,
+		Tag{Name: "graphql", Description: "The GraphQL API. Introspect it for its schema."},
+		Tag{Name: "service", Description: "Health and documentation of the service."},
+		Tag{Name: "admin", Description: "Management of the cache. Only served when the service is started with an admin token, which every request must carry as a bearer token."},
+	)
+	addPath(doc, http.MethodGet, "/graphql", graphqlGet(s))
+	addPath(doc, http.MethodPost, "/graphql", graphqlPost(s))
+	addPath(doc, http.MethodGet, "/health", health())
+	addPath(doc, http.MethodGet, "/openapi.json", spec())
+	addPath(doc, http.MethodGet, "/admin/cache", cacheStats(s))
+	addPath(doc, http.MethodDelete, "/admin/cache", purgeCache())
+	addPath(doc, http.MethodPost, "/admin/cache/warm", warmCache())
+
+	for _, item := range doc.Paths {
+		for _, op := range item.Operations() {
+			addHeaders(op, map[string]*Header{
+				handler.RequestIDHeader: {Description: "The ID of the request, reused from the request header when set.", Schema: &Schema{Type: "string"}},
+			})
+		}
+	}
+	for name, desc := range schemaDescriptions {
+		if schema, ok := s.components[name]; ok {
+			schema.Description = desc
+		}
+	}
+	doc.Components.Schemas = s.components
+	doc.Components.SecuritySchemes = map[string]*SecurityScheme{
+		adminAuth: {Type: "http", Scheme: "bearer", Description: "The admin token of the service."},
+	}
+	return doc
+}
+
+func groupDescription(g Group) string {
+	desc := fmt.Sprintf("Country endpoints of %s. Countries are rendered in the %s view by default.", g.Version.Name, g.Version.DefaultView)
+	if g.name() == Unversioned {
+		desc = "Country endpoints predating versioning, served as v1. Countries are rendered in the compact view by default."
+	}
+	if !g.Version.Deprecated.IsZero() {
+		desc += fmt.Sprintf(" Deprecated in favor of %s", g.Version.Successor)
+		if !g.Version.Sunset.IsZero() {
+			desc += ", sunset on " + g.Version.Sunset.Format("2 January 2006")
+		}
+		desc += "."
+	}
+	return desc
+}
+
+func addPath(doc *Document, method, path string, op *Operation) {
+	item, ok := doc.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		doc.Paths[path] = item
+	}
+	switch method {
+	case http.MethodGet:
+		item.Get = op
+	case http.MethodPost:
+		item.Post = op
+	case http.MethodDelete:
+		item.Delete = op
+	default:
+		panic("openapi: unsupported method " + method)
+	}
+}
+
+// addHeaders documents headers sent with every response of op.
+func addHeaders(op *Operation, headers map[string]*Header) {
+	for _, resp := range op.Responses {
+		if resp.Headers == nil {
+			resp.Headers = make(map[string]*Header)
+		}
+		for name, h := range headers {
+			resp.Headers[name] = h
+		}
+	}
+}
+
+func deprecationHeaders(v handler.APIVersion) map[string]*Header {
+	headers := map[string]*Header{
+		"Deprecation": {
+			Description: fmt.Sprintf("When the version was deprecated, as @ and Unix seconds: @%d.", v.Deprecated.Unix()),
+			Schema:      &Schema{Type: "string"},
+		},
+	}
+	if !v.Sunset.IsZero() {
+		headers["Sunset"] = &Header{
+			Description: "When the version stops being served: " + v.Sunset.UTC().Format(http.TimeFormat) + ".",
+			Schema:      &Schema{Type: "string"},
+		}
+	}
+	if v.Successor != "" {
+		headers["Link"] = &Header{
+			Description: fmt.Sprintf(`The same resource under %s, with rel="successor-version".`, v.Successor),
+			Schema:      &Schema{Type: "string"},
+		}
+	}
+	return headers
+}
+
+// endpoint is a country endpoint, relative to the prefix of a group.
+type endpoint struct {
+	method string
+	path   string
+	op     *Operation
+}
+
+// countryEndpoints describes the country endpoints as served by version v.
+// Every call returns new operations, so they can be adapted to a group.
+func countryEndpoints(s *schemas, v handler.APIVersion) []endpoint {
+	country := s.of(models.CountryMetadata{})
+	limit := query("limit", "The maximum number of results.",
+		&Schema{Type: "integer", Minimum: num(1), Maximum: num(50), Default: 10})
+
+	return []endpoint{
+		{http.MethodGet, "/countries", &Operation{
+			OperationID: "listCountries",
+			Summary:     "List and search countries",
+			Description: "Lists the countries matching every filter, one page at a time. Search terms in q rank countries by relevance unless sort says otherwise.",
+			Parameters: append([]*Parameter{
+				query("q", "Full-text search terms, matched against names, alternative spellings, translations, capitals, languages and currencies. Every word must match.", &Schema{Type: "string"}),
+				query("region", "Keeps the countries of a region, such as Europe, ignoring case.", &Schema{Type: "string"}),
+				query("language", "Keeps the countries speaking a language, by English name or ISO 639-3 code.", &Schema{Type: "string"}),
+				query("currency", "Keeps the countries using a currency, by ISO 4217 code or symbol.", &Schema{Type: "string"}),
+				query("minPopulation", "Keeps the countries with at least this population.", &Schema{Type: "integer", Minimum: num(0)}),
+				query("maxPopulation", "Keeps the countries with at most this population.", &Schema{Type: "integer", Minimum: num(0)}),
+				query("landlocked", "Keeps the landlocked countries, or the others.", &Schema{Type: "boolean"}),
+				query("sort", "The sort key, prefixed with - for descending order. Defaults to relevance for searches and to name otherwise.",
+					&Schema{Type: "string", Enum: sortKeys()}),
+				limit,
+				query("cursor", "The nextCursor of the previous page.", &Schema{Type: "string"}),
+			}, projectionParams(s, v)...),
+			Responses: withProblems(map[string]*Response{
+				"200": jsonResponse("A page of countries.", s.pageSchema()),
+			}, 400, 406, 503),
+		}},
+		{http.MethodGet, "/countries/search", &Operation{
+			OperationID: "searchCountries",
+			Summary:     "Find a country by name",
+			Description: "Matches common, official, native and translated names. Responses carry validators and lifetimes for HTTP caching.",
+			Parameters: append([]*Parameter{
+				required(query("name", "The name of the country.", &Schema{Type: "string", MaxLength: service.MaxNameLength})),
+			}, projectionParams(s, v)...),
+			Responses: withProblems(map[string]*Response{
+				"200": withCaching(jsonResponse("The country.", country)),
+				"304": {Description: "The copy named by If-None-Match or If-Modified-Since is still current."},
+			}, 400, 404, 406, 502, 503, 504),
+		}},
+		{http.MethodGet, "/countries/by-code", &Operation{
+			OperationID: "searchByCode",
+			Summary:     "Find a country by code",
+			Parameters: append([]*Parameter{
+				required(query("code", "An ISO 3166 alpha-2, alpha-3 or numeric code, or an IOC code: 2 or 3 letters or 3 digits.",
+					&Schema{Type: "string", MaxLength: 3})),
+			}, projectionParams(s, v)...),
+			Responses: withProblems(map[string]*Response{
+				"200": jsonResponse("The country.", country),
+			}, 400, 404, 406, 502, 503, 504),
+		}},
+		{http.MethodGet, "/countries/by-capital", &Operation{
+			OperationID: "searchByCapital",
+			Summary:     "Find a country by capital",
+			Parameters: append([]*Parameter{
+				required(query("capital", "The name of the capital.", &Schema{Type: "string", MaxLength: service.MaxNameLength})),
+			}, projectionParams(s, v)...),
+			Responses: withProblems(map[string]*Response{
+				"200": jsonResponse("The country.", country),
+			}, 400, 404, 406, 502, 503, 504),
+		}},
+		{http.MethodGet, "/countries/by-currency", &Operation{
+			OperationID: "searchByCurrency",
+			Summary:     "Find the countries using a currency",
+			Parameters: append([]*Parameter{
+				required(query("currency", "An ISO 4217 code such as EUR, or a symbol such as €.",
+					&Schema{Type: "string", MaxLength: service.MaxSymbolLength})),
+			}, projectionParams(s, v)...),
+			Responses: withProblems(map[string]*Response{
+				"200": jsonResponse("The countries using the currency.", arrayOf(s.of(models.CurrencyMatch{}))),
+			}, 400, 404, 406, 502, 503, 504),
+		}},
+		{http.MethodGet, "/countries/fuzzy", &Operation{
+			OperationID: "fuzzySearch",
+			Summary:     "Find countries by approximate name",
+			Description: "Tolerates typos, ranking the countries by how closely one of their names matches.",
+			Parameters: append([]*Parameter{
+				required(query("q", "The approximate name.", &Schema{Type: "string", MaxLength: service.MaxNameLength})),
+				query("threshold", "The minimum similarity of a match, from 0 to 1.",
+					&Schema{Type: "number", Minimum: num(0), Maximum: num(1), Default: service.DefaultFuzzyThreshold}),
+				limit,
+			}, projectionParams(s, v)...),
+			Responses: withProblems(map[string]*Response{
+				"200": jsonResponse("The closest countries first.", arrayOf(s.of(models.FuzzyMatch{}))),
+			}, 400, 406, 503),
+		}},
+		{http.MethodGet, "/countries/suggest", &Operation{
+			OperationID: "suggest",
+			Summary:     "Autocomplete country names",
+			Parameters: []*Parameter{
+				required(query("q", "The typed prefix.", &Schema{Type: "string", MaxLength: service.MaxNameLength})),
+				limit,
+				prettyParam(),
+			},
+			Responses: withProblems(map[string]*Response{
+				"200": jsonResponse("The most populous countries with a name starting with the prefix first.", arrayOf(s.of(models.Suggestion{}))),
+			}, 400, 406, 503),
+		}},
+		{http.MethodPost, "/countries/batch", &Operation{
+			OperationID: "batchLookup",
+			Summary:     "Look up many countries at once",
+			Description: fmt.Sprintf("Looks up to %d countries by name or code. In partial mode every item gets its country or its problem; in atomic mode the first failure fails the batch.", service.MaxBatchSize),
+			Parameters:  projectionParams(s, v),
+			RequestBody: &RequestBody{Required: true, Content: map[string]*MediaType{
+				"application/json": {Schema: s.batchRequestSchema()},
+			}},
+			Responses: withProblems(map[string]*Response{
+				"200": jsonResponse("The result of every item, in order.", s.batchResponseSchema()),
+			}, 400, 404, 406, 413, 502, 503, 504),
+		}},
+		{http.MethodGet, "/countries/export", &Operation{
+			OperationID: "exportCountries",
+			Summary:     "Export every country",
+			Description: "Streams every country as NDJSON, one JSON document per line, or as CSV with a header row.",
+			Parameters: append([]*Parameter{
+				query("format", "The export format. Negotiated from Accept when absent, defaulting to ndjson.",
+					&Schema{Type: "string", Enum: []string{handler.FormatNDJSON, handler.FormatCSV}}),
+			}, projectionParams(s, v)...),
+			Responses: withProblems(map[string]*Response{
+				"200": {
+					Description: "The countries.",
+					Headers: map[string]*Header{
+						"Content-Disposition": {Description: "Names the file countries.ndjson or countries.csv.", Schema: &Schema{Type: "string"}},
+					},
+					Content: map[string]*MediaType{
+						handler.NDJSONContentType: {Schema: country},
+						"text/csv":                {Schema: &Schema{Type: "string"}},
+					},
+				},
+			}, 400, 406, 503),
+		}},
+	}
+}
+
+// projectionParams are the parameters choosing how countries are rendered.
+func projectionParams(s *schemas, v handler.APIVersion) []*Parameter {
+	s.of(models.CountryMetadata{})
+	var fields []string
+	for name := range s.components["CountryMetadata"].Properties {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+
+	return []*Parameter{
+		query("view", "The view countries are rendered in. Ignored when fields is set.",
+			&Schema{Type: "string", Enum: []string{handler.ViewCompact, handler.ViewFull}, Default: v.DefaultView}),
+		query("fields", "A comma-separated list of the fields to render, out of "+strings.Join(fields, ", ")+".",
+			&Schema{Type: "string"}),
+		query("lang", "The ISO 639-1 language of country names, winning over Accept-Language. Unsupported languages fall back to English.",
+			&Schema{Type: "string"}),
+		prettyParam(),
+	}
+}
+
+func prettyParam() *Parameter {
+	return query("pretty", "Indents the response, in the formats that have a choice.", &Schema{Type: "boolean"})
+}
+
+func sortKeys() []string {
+	var keys []string
+	for _, key := range []string{service.SortName, service.SortPopulation, service.SortArea} {
+		keys = append(keys, key, "-"+key)
+	}
+	return keys
+}
+
+func query(name, desc string, schema *Schema) *Parameter {
+	return &Parameter{Name: name, In: "query", Description: desc, Schema: schema}
+}
+
+func required(p *Parameter) *Parameter {
+	p.Required = true
+	return p
+}
+
+func num(f float64) *float64 {
+	return &f
+}
+
+func arrayOf(items *Schema) *Schema {
+	return &Schema{Type: "array", Items: items}
+}
+
+func jsonResponse(desc string, schema *Schema) *Response {
+	return &Response{
+		Description: desc,
+		Content:     map[string]*MediaType{"application/json": {Schema: schema}},
+	}
+}
+
+// withProblems adds the problem documents answered with the given statuses,
+// and with 500, to responses.
+func withProblems(responses map[string]*Response, statuses ...int) map[string]*Response {
+	for _, status := range append(statuses, http.StatusInternalServerError) {
+		responses[strconv.Itoa(status)] = &Response{
+			Description: http.StatusText(status),
+			Content:     map[string]*MediaType{handler.ProblemContentType: {Schema: Ref("Problem")}},
+		}
+	}
+	return responses
+}
+
+// withCaching documents the validators and lifetimes of a cacheable
+// response.
+func withCaching(resp *Response) *Response {
+	resp.Headers = map[string]*Header{
+		"ETag":          {Description: "A strong validator of the body.", Schema: &Schema{Type: "string"}},
+		"Last-Modified": {Description: "When the country was fetched from upstream.", Schema: &Schema{Type: "string"}},
+		"Cache-Control": {Description: "How long the response stays fresh.", Schema: &Schema{Type: "string"}},
+	}
+	return resp
+}
+
+func (s *schemas) pageSchema() *Schema {
+	if _, ok := s.components["CountryPage"]; !ok {
+		s.components["CountryPage"] = &Schema{
+			Type:        "object",
+			Description: "A page of countries with the links to browse the others.",
+			Properties: map[string]*Schema{
+				"data":       arrayOf(s.of(models.SearchHit{})),
+				"total":      {Type: "integer", Format: "int64", Description: "The number of countries matching the query across all pages."},
+				"nextCursor": {Type: "string", Description: "Fetches the next page. Absent on the last one."},
+				"links": {
+					Type: "object",
+					Properties: map[string]*Schema{
+						"self": {Type: "string"},
+						"next": {Type: "string"},
+					},
+					Required: []string{"self"},
+				},
+			},
+			Required: []string{"data", "total", "links"},
+		}
+	}
+	return Ref("CountryPage")
+}
+
+func (s *schemas) batchRequestSchema() *Schema {
+	if _, ok := s.components["BatchRequest"]; !ok {
+		s.components["BatchRequest"] = &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"items": {Type: "array", Items: s.of(models.BatchItem{}), MaxItems: service.MaxBatchSize},
+				"mode":  {Type: "string", Enum: []string{handler.BatchPartial, handler.BatchAtomic}, Default: handler.BatchPartial},
+			},
+			Required: []string{"items"},
+		}
+	}
+	return Ref("BatchRequest")
+}
+
+func (s *schemas) batchResponseSchema() *Schema {
+	if _, ok := s.components["BatchResponse"]; !ok {
+		s.components["BatchResult"] = &Schema{
+			Type:        "object",
+			Description: "An item of a batch with either its country or its problem.",
+			Properties: map[string]*Schema{
+				"name":    {Type: "string"},
+				"code":    {Type: "string"},
+				"country": s.of(models.CountryMetadata{}),
+				"error":   s.of(models.Problem{}),
+			},
+		}
+		s.components["BatchResponse"] = &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"results":   arrayOf(Ref("BatchResult")),
+				"succeeded": {Type: "integer", Format: "int64"},
+				"failed":    {Type: "integer", Format: "int64"},
+			},
+			Required: []string{"results", "succeeded", "failed"},
+		}
+	}
+	return Ref("BatchResponse")
+}
+
+func health() *Operation {
+	return &Operation{
+		OperationID: "health",
+		Summary:     "Check that the service is running",
+		Tags:        []string{"service"},
+		Responses: map[string]*Response{
+			"200": jsonResponse("The service is running.", &Schema{
+				Type:       "object",
+				Properties: map[string]*Schema{"status": {Type: "string", Enum: []string{"OK"}}},
+				Required:   []string{"status"},
+			}),
+		},
+	}
+}
+
+func spec() *Operation {
+	return &Operation{
+		OperationID: "openapi",
+		Summary:     "This document",
+		Tags:        []string{"service"},
+		Responses: map[string]*Response{
+			"200": jsonResponse("The OpenAPI document of the service.", &Schema{Type: "object"}),
+		},
+	}
+}
+
+func graphqlGet(s *schemas) *Operation {
+	return &Operation{
+		OperationID: "graphqlQuery",
+		Summary:     "Run a GraphQL query",
+		Tags:        []string{"graphql"},
+		Parameters: []*Parameter{
+			required(query("query", "The GraphQL document.", &Schema{Type: "string"})),
+			query("operationName", "The operation to run, when the document has several.", &Schema{Type: "string"}),
+			query("variables", "The variables of the operation, as a JSON object.", &Schema{Type: "string"}),
+		},
+		Responses: graphqlResponses(s),
+	}
+}
+
+func graphqlPost(s *schemas) *Operation {
+	return &Operation{
+		OperationID: "graphqlPost",
+		Summary:     "Run a GraphQL query or mutation",
+		Tags:        []string{"graphql"},
+		RequestBody: &RequestBody{Required: true, Content: map[string]*MediaType{
+			"application/json": {Schema: &Schema{
+				Type: "object",
+				Properties: map[string]*Schema{
+					"query":         {Type: "string"},
+					"operationName": {Type: "string"},
+					"variables":     {Type: "object", AdditionalProperties: &Schema{}},
+				},
+				Required: []string{"query"},
+			}},
+		}},
+		Responses: graphqlResponses(s),
+	}
+}
+
+func graphqlResponses(s *schemas) map[string]*Response {
+	if _, ok := s.components["GraphQLResponse"]; !ok {
+		s.components["GraphQLResponse"] = &Schema{
+			Type:        "object",
+			Description: "The result of a GraphQL request.",
+			Properties: map[string]*Schema{
+				"data": {Type: "object", AdditionalProperties: &Schema{}},
+				"errors": arrayOf(&Schema{
+					Type: "object",
+					Properties: map[string]*Schema{
+						"message":    {Type: "string"},
+						"locations":  arrayOf(&Schema{Type: "object", AdditionalProperties: &Schema{Type: "integer"}}),
+						"path":       arrayOf(&Schema{}),
+						"extensions": {Type: "object", AdditionalProperties: &Schema{}},
+					},
+					Required: []string{"message"},
+				}),
+			},
+		}
+	}
+	return map[string]*Response{
+		"200": jsonResponse("The query was executed; errors lists the fields that failed.", Ref("GraphQLResponse")),
+		"400": jsonResponse("The request was not a valid query, or exceeded the depth or complexity limits.", Ref("GraphQLResponse")),
+	}
+}
+
+// adminAuth names the security scheme of the admin endpoints.
+const adminAuth = "adminToken"
+
+// adminOperation secures op with the admin token.
+func adminOperation(op *Operation) *Operation {
+	op.Tags = []string{"admin"}
+	op.Security = []map[string][]string{{adminAuth: {}}}
+	op.Responses = withProblems(op.Responses, 401, 406)
+	op.Responses["401"].Headers = map[string]*Header{
+		"WWW-Authenticate": {Description: "Asks for a bearer token.", Schema: &Schema{Type: "string"}},
+	}
+	return op
+}
+
+func cacheStats(s *schemas) *Operation {
+	return adminOperation(&Operation{
+		OperationID: "cacheStats",
+		Summary:     "Report the size and use of the cache",
+		Parameters:  []*Parameter{prettyParam()},
+		Responses: map[string]*Response{
+			"200": jsonResponse("The statistics of the cache since the service started.", s.of(models.CacheStats{})),
+		},
+	})
+}
+
+func purgeCache() *Operation {
+	return adminOperation(&Operation{
+		OperationID: "purgeCache",
+		Summary:     "Empty the cache",
+		Parameters:  []*Parameter{prettyParam()},
+		Responses: map[string]*Response{
+			"200": jsonResponse("The number of entries removed.", &Schema{
+				Type:       "object",
+				Properties: map[string]*Schema{"purged": {Type: "integer", Format: "int64"}},
+				Required:   []string{"purged"},
+			}),
+		},
+	})
+}
+
+func warmCache() *Operation {
+	op := adminOperation(&Operation{
+		OperationID: "warmCache",
+		Summary:     "Load every country into the cache",
+		Description: "Fetches every country from upstream, caches each under its name, codes and capitals, and rebuilds the local index with them.",
+		Parameters:  []*Parameter{prettyParam()},
+		Responses: map[string]*Response{
+			"200": jsonResponse("The number of countries cached.", &Schema{
+				Type:       "object",
+				Properties: map[string]*Schema{"warmed": {Type: "integer", Format: "int64"}},
+				Required:   []string{"warmed"},
+			}),
+		},
+	})
+	op.Responses = withProblems(op.Responses, 502, 503, 504)
+	return op
+}
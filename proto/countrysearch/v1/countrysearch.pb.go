@@ -0,0 +1,1128 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        v5.29.3
+// source: countrysearch/v1/countrysearch.proto
+
+package countrysearchv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SearchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Fields restricts the response to the named fields of the REST model,
+	// such as "name" or "capitals". Empty means every field.
+	Fields        []string `protobuf:"bytes,2,rep,name=fields,proto3" json:"fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_countrysearch_v1_countrysearch_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_countrysearch_v1_countrysearch_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_countrysearch_v1_countrysearch_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SearchRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type GetByCodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Fields        []string               `protobuf:"bytes,2,rep,name=fields,proto3" json:"fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetByCodeRequest) Reset() {
+	*x = GetByCodeRequest{}
+	mi := &file_countrysearch_v1_countrysearch_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetByCodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetByCodeRequest) ProtoMessage() {}
+
+func (x *GetByCodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_countrysearch_v1_countrysearch_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetByCodeRequest.ProtoReflect.Descriptor instead.
+func (*GetByCodeRequest) Descriptor() ([]byte, []int) {
+	return file_countrysearch_v1_countrysearch_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetByCodeRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *GetByCodeRequest) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type BatchItem struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Key:
+	//
+	//	*BatchItem_Name
+	//	*BatchItem_Code
+	Key           isBatchItem_Key `protobuf_oneof:"key"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchItem) Reset() {
+	*x = BatchItem{}
+	mi := &file_countrysearch_v1_countrysearch_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchItem) ProtoMessage() {}
+
+func (x *BatchItem) ProtoReflect() protoreflect.Message {
+	mi := &file_countrysearch_v1_countrysearch_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchItem.ProtoReflect.Descriptor instead.
+func (*BatchItem) Descriptor() ([]byte, []int) {
+	return file_countrysearch_v1_countrysearch_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BatchItem) GetKey() isBatchItem_Key {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *BatchItem) GetName() string {
+	if x != nil {
+		if x, ok := x.Key.(*BatchItem_Name); ok {
+			return x.Name
+		}
+	}
+	return ""
+}
+
+func (x *BatchItem) GetCode() string {
+	if x != nil {
+		if x, ok := x.Key.(*BatchItem_Code); ok {
+			return x.Code
+		}
+	}
+	return ""
+}
+
+type isBatchItem_Key interface {
+	isBatchItem_Key()
+}
+
+type BatchItem_Name struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3,oneof"`
+}
+
+type BatchItem_Code struct {
+	Code string `protobuf:"bytes,2,opt,name=code,proto3,oneof"`
+}
+
+func (*BatchItem_Name) isBatchItem_Key() {}
+
+func (*BatchItem_Code) isBatchItem_Key() {}
+
+type BatchGetRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Items []*BatchItem           `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	// Atomic fails the whole call when any item fails. Otherwise each item
+	// gets its own result.
+	Atomic        bool     `protobuf:"varint,2,opt,name=atomic,proto3" json:"atomic,omitempty"`
+	Fields        []string `protobuf:"bytes,3,rep,name=fields,proto3" json:"fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetRequest) Reset() {
+	*x = BatchGetRequest{}
+	mi := &file_countrysearch_v1_countrysearch_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetRequest) ProtoMessage() {}
+
+func (x *BatchGetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_countrysearch_v1_countrysearch_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetRequest) Descriptor() ([]byte, []int) {
+	return file_countrysearch_v1_countrysearch_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BatchGetRequest) GetItems() []*BatchItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *BatchGetRequest) GetAtomic() bool {
+	if x != nil {
+		return x.Atomic
+	}
+	return false
+}
+
+func (x *BatchGetRequest) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type BatchGetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*BatchResult         `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetResponse) Reset() {
+	*x = BatchGetResponse{}
+	mi := &file_countrysearch_v1_countrysearch_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetResponse) ProtoMessage() {}
+
+func (x *BatchGetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_countrysearch_v1_countrysearch_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetResponse) Descriptor() ([]byte, []int) {
+	return file_countrysearch_v1_countrysearch_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BatchGetResponse) GetResults() []*BatchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type BatchResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Item  *BatchItem             `protobuf:"bytes,1,opt,name=item,proto3" json:"item,omitempty"`
+	// Types that are valid to be assigned to Result:
+	//
+	//	*BatchResult_Country
+	//	*BatchResult_Error
+	Result        isBatchResult_Result `protobuf_oneof:"result"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchResult) Reset() {
+	*x = BatchResult{}
+	mi := &file_countrysearch_v1_countrysearch_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchResult) ProtoMessage() {}
+
+func (x *BatchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_countrysearch_v1_countrysearch_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchResult.ProtoReflect.Descriptor instead.
+func (*BatchResult) Descriptor() ([]byte, []int) {
+	return file_countrysearch_v1_countrysearch_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BatchResult) GetItem() *BatchItem {
+	if x != nil {
+		return x.Item
+	}
+	return nil
+}
+
+func (x *BatchResult) GetResult() isBatchResult_Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *BatchResult) GetCountry() *Country {
+	if x != nil {
+		if x, ok := x.Result.(*BatchResult_Country); ok {
+			return x.Country
+		}
+	}
+	return nil
+}
+
+func (x *BatchResult) GetError() *Error {
+	if x != nil {
+		if x, ok := x.Result.(*BatchResult_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+type isBatchResult_Result interface {
+	isBatchResult_Result()
+}
+
+type BatchResult_Country struct {
+	Country *Country `protobuf:"bytes,2,opt,name=country,proto3,oneof"`
+}
+
+type BatchResult_Error struct {
+	Error *Error `protobuf:"bytes,3,opt,name=error,proto3,oneof"`
+}
+
+func (*BatchResult_Country) isBatchResult_Result() {}
+
+func (*BatchResult_Error) isBatchResult_Result() {}
+
+// Error is the failure of one item of a batch.
+type Error struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Code is a google.rpc.Code value, such as 5 for NOT_FOUND.
+	Code          int32  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	mi := &file_countrysearch_v1_countrysearch_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_countrysearch_v1_countrysearch_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_countrysearch_v1_countrysearch_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Error) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *Error) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ListRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Query holds full-text search terms. Empty lists every country.
+	Query         string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Region        string `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"`
+	MinPopulation *int64 `protobuf:"varint,3,opt,name=min_population,json=minPopulation,proto3,oneof" json:"min_population,omitempty"`
+	MaxPopulation *int64 `protobuf:"varint,4,opt,name=max_population,json=maxPopulation,proto3,oneof" json:"max_population,omitempty"`
+	Language      string `protobuf:"bytes,5,opt,name=language,proto3" json:"language,omitempty"`
+	Currency      string `protobuf:"bytes,6,opt,name=currency,proto3" json:"currency,omitempty"`
+	Landlocked    *bool  `protobuf:"varint,7,opt,name=landlocked,proto3,oneof" json:"landlocked,omitempty"`
+	// Sort is "name", "population" or "area", prefixed with "-" for
+	// descending order.
+	Sort          string `protobuf:"bytes,8,opt,name=sort,proto3" json:"sort,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRequest) Reset() {
+	*x = ListRequest{}
+	mi := &file_countrysearch_v1_countrysearch_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest) ProtoMessage() {}
+
+func (x *ListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_countrysearch_v1_countrysearch_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return file_countrysearch_v1_countrysearch_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *ListRequest) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *ListRequest) GetMinPopulation() int64 {
+	if x != nil && x.MinPopulation != nil {
+		return *x.MinPopulation
+	}
+	return 0
+}
+
+func (x *ListRequest) GetMaxPopulation() int64 {
+	if x != nil && x.MaxPopulation != nil {
+		return *x.MaxPopulation
+	}
+	return 0
+}
+
+func (x *ListRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *ListRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *ListRequest) GetLandlocked() bool {
+	if x != nil && x.Landlocked != nil {
+		return *x.Landlocked
+	}
+	return false
+}
+
+func (x *ListRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+type Country struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Name         string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Population   int64                  `protobuf:"varint,2,opt,name=population,proto3" json:"population,omitempty"`
+	Capital      string                 `protobuf:"bytes,3,opt,name=capital,proto3" json:"capital,omitempty"`
+	Currency     string                 `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"`
+	Cca2         string                 `protobuf:"bytes,5,opt,name=cca2,proto3" json:"cca2,omitempty"`
+	Cca3         string                 `protobuf:"bytes,6,opt,name=cca3,proto3" json:"cca3,omitempty"`
+	Ccn3         string                 `protobuf:"bytes,7,opt,name=ccn3,proto3" json:"ccn3,omitempty"`
+	Cioc         string                 `protobuf:"bytes,8,opt,name=cioc,proto3" json:"cioc,omitempty"`
+	Capitals     []string               `protobuf:"bytes,9,rep,name=capitals,proto3" json:"capitals,omitempty"`
+	Currencies   []*Currency            `protobuf:"bytes,10,rep,name=currencies,proto3" json:"currencies,omitempty"`
+	Region       string                 `protobuf:"bytes,11,opt,name=region,proto3" json:"region,omitempty"`
+	Subregion    string                 `protobuf:"bytes,12,opt,name=subregion,proto3" json:"subregion,omitempty"`
+	Languages    []*Language            `protobuf:"bytes,13,rep,name=languages,proto3" json:"languages,omitempty"`
+	Borders      []string               `protobuf:"bytes,14,rep,name=borders,proto3" json:"borders,omitempty"`
+	Latlng       []float64              `protobuf:"fixed64,15,rep,packed,name=latlng,proto3" json:"latlng,omitempty"`
+	Area         float64                `protobuf:"fixed64,16,opt,name=area,proto3" json:"area,omitempty"`
+	Landlocked   bool                   `protobuf:"varint,17,opt,name=landlocked,proto3" json:"landlocked,omitempty"`
+	Timezones    []string               `protobuf:"bytes,18,rep,name=timezones,proto3" json:"timezones,omitempty"`
+	Flags        *Flags                 `protobuf:"bytes,19,opt,name=flags,proto3" json:"flags,omitempty"`
+	CallingCodes []string               `protobuf:"bytes,20,rep,name=calling_codes,json=callingCodes,proto3" json:"calling_codes,omitempty"`
+	Tld          []string               `protobuf:"bytes,21,rep,name=tld,proto3" json:"tld,omitempty"`
+	// Translations maps ISO 639-3 language codes to the common name of the
+	// country in that language.
+	Translations  map[string]string `protobuf:"bytes,22,rep,name=translations,proto3" json:"translations,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Country) Reset() {
+	*x = Country{}
+	mi := &file_countrysearch_v1_countrysearch_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Country) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Country) ProtoMessage() {}
+
+func (x *Country) ProtoReflect() protoreflect.Message {
+	mi := &file_countrysearch_v1_countrysearch_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Country.ProtoReflect.Descriptor instead.
+func (*Country) Descriptor() ([]byte, []int) {
+	return file_countrysearch_v1_countrysearch_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Country) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Country) GetPopulation() int64 {
+	if x != nil {
+		return x.Population
+	}
+	return 0
+}
+
+func (x *Country) GetCapital() string {
+	if x != nil {
+		return x.Capital
+	}
+	return ""
+}
+
+func (x *Country) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Country) GetCca2() string {
+	if x != nil {
+		return x.Cca2
+	}
+	return ""
+}
+
+func (x *Country) GetCca3() string {
+	if x != nil {
+		return x.Cca3
+	}
+	return ""
+}
+
+func (x *Country) GetCcn3() string {
+	if x != nil {
+		return x.Ccn3
+	}
+	return ""
+}
+
+func (x *Country) GetCioc() string {
+	if x != nil {
+		return x.Cioc
+	}
+	return ""
+}
+
+func (x *Country) GetCapitals() []string {
+	if x != nil {
+		return x.Capitals
+	}
+	return nil
+}
+
+func (x *Country) GetCurrencies() []*Currency {
+	if x != nil {
+		return x.Currencies
+	}
+	return nil
+}
+
+func (x *Country) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *Country) GetSubregion() string {
+	if x != nil {
+		return x.Subregion
+	}
+	return ""
+}
+
+func (x *Country) GetLanguages() []*Language {
+	if x != nil {
+		return x.Languages
+	}
+	return nil
+}
+
+func (x *Country) GetBorders() []string {
+	if x != nil {
+		return x.Borders
+	}
+	return nil
+}
+
+func (x *Country) GetLatlng() []float64 {
+	if x != nil {
+		return x.Latlng
+	}
+	return nil
+}
+
+func (x *Country) GetArea() float64 {
+	if x != nil {
+		return x.Area
+	}
+	return 0
+}
+
+func (x *Country) GetLandlocked() bool {
+	if x != nil {
+		return x.Landlocked
+	}
+	return false
+}
+
+func (x *Country) GetTimezones() []string {
+	if x != nil {
+		return x.Timezones
+	}
+	return nil
+}
+
+func (x *Country) GetFlags() *Flags {
+	if x != nil {
+		return x.Flags
+	}
+	return nil
+}
+
+func (x *Country) GetCallingCodes() []string {
+	if x != nil {
+		return x.CallingCodes
+	}
+	return nil
+}
+
+func (x *Country) GetTld() []string {
+	if x != nil {
+		return x.Tld
+	}
+	return nil
+}
+
+func (x *Country) GetTranslations() map[string]string {
+	if x != nil {
+		return x.Translations
+	}
+	return nil
+}
+
+type Currency struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Symbol        string                 `protobuf:"bytes,3,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Currency) Reset() {
+	*x = Currency{}
+	mi := &file_countrysearch_v1_countrysearch_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Currency) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Currency) ProtoMessage() {}
+
+func (x *Currency) ProtoReflect() protoreflect.Message {
+	mi := &file_countrysearch_v1_countrysearch_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Currency.ProtoReflect.Descriptor instead.
+func (*Currency) Descriptor() ([]byte, []int) {
+	return file_countrysearch_v1_countrysearch_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Currency) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Currency) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Currency) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+type Language struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Language) Reset() {
+	*x = Language{}
+	mi := &file_countrysearch_v1_countrysearch_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Language) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Language) ProtoMessage() {}
+
+func (x *Language) ProtoReflect() protoreflect.Message {
+	mi := &file_countrysearch_v1_countrysearch_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Language.ProtoReflect.Descriptor instead.
+func (*Language) Descriptor() ([]byte, []int) {
+	return file_countrysearch_v1_countrysearch_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Language) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Language) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type Flags struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Png           string                 `protobuf:"bytes,1,opt,name=png,proto3" json:"png,omitempty"`
+	Svg           string                 `protobuf:"bytes,2,opt,name=svg,proto3" json:"svg,omitempty"`
+	Alt           string                 `protobuf:"bytes,3,opt,name=alt,proto3" json:"alt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Flags) Reset() {
+	*x = Flags{}
+	mi := &file_countrysearch_v1_countrysearch_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Flags) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Flags) ProtoMessage() {}
+
+func (x *Flags) ProtoReflect() protoreflect.Message {
+	mi := &file_countrysearch_v1_countrysearch_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Flags.ProtoReflect.Descriptor instead.
+func (*Flags) Descriptor() ([]byte, []int) {
+	return file_countrysearch_v1_countrysearch_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Flags) GetPng() string {
+	if x != nil {
+		return x.Png
+	}
+	return ""
+}
+
+func (x *Flags) GetSvg() string {
+	if x != nil {
+		return x.Svg
+	}
+	return ""
+}
+
+func (x *Flags) GetAlt() string {
+	if x != nil {
+		return x.Alt
+	}
+	return ""
+}
+
+var File_countrysearch_v1_countrysearch_proto protoreflect.FileDescriptor
+
+const file_countrysearch_v1_countrysearch_proto_rawDesc = "" +
+	"\n" +
+	"$countrysearch/v1/countrysearch.proto\x12\x1asearchsvc.countrysearch.v1\";\n" +
+	"\rSearchRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06fields\x18\x02 \x03(\tR\x06fields\">\n" +
+	"\x10GetByCodeRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x16\n" +
+	"\x06fields\x18\x02 \x03(\tR\x06fields\">\n" +
+	"\tBatchItem\x12\x14\n" +
+	"\x04name\x18\x01 \x01(\tH\x00R\x04name\x12\x14\n" +
+	"\x04code\x18\x02 \x01(\tH\x00R\x04codeB\x05\n" +
+	"\x03key\"~\n" +
+	"\x0fBatchGetRequest\x12;\n" +
+	"\x05items\x18\x01 \x03(\v2%.searchsvc.countrysearch.v1.BatchItemR\x05items\x12\x16\n" +
+	"\x06atomic\x18\x02 \x01(\bR\x06atomic\x12\x16\n" +
+	"\x06fields\x18\x03 \x03(\tR\x06fields\"U\n" +
+	"\x10BatchGetResponse\x12A\n" +
+	"\aresults\x18\x01 \x03(\v2'.searchsvc.countrysearch.v1.BatchResultR\aresults\"\xce\x01\n" +
+	"\vBatchResult\x129\n" +
+	"\x04item\x18\x01 \x01(\v2%.searchsvc.countrysearch.v1.BatchItemR\x04item\x12?\n" +
+	"\acountry\x18\x02 \x01(\v2#.searchsvc.countrysearch.v1.CountryH\x00R\acountry\x129\n" +
+	"\x05error\x18\x03 \x01(\v2!.searchsvc.countrysearch.v1.ErrorH\x00R\x05errorB\b\n" +
+	"\x06result\"5\n" +
+	"\x05Error\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xb9\x02\n" +
+	"\vListRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x16\n" +
+	"\x06region\x18\x02 \x01(\tR\x06region\x12*\n" +
+	"\x0emin_population\x18\x03 \x01(\x03H\x00R\rminPopulation\x88\x01\x01\x12*\n" +
+	"\x0emax_population\x18\x04 \x01(\x03H\x01R\rmaxPopulation\x88\x01\x01\x12\x1a\n" +
+	"\blanguage\x18\x05 \x01(\tR\blanguage\x12\x1a\n" +
+	"\bcurrency\x18\x06 \x01(\tR\bcurrency\x12#\n" +
+	"\n" +
+	"landlocked\x18\a \x01(\bH\x02R\n" +
+	"landlocked\x88\x01\x01\x12\x12\n" +
+	"\x04sort\x18\b \x01(\tR\x04sortB\x11\n" +
+	"\x0f_min_populationB\x11\n" +
+	"\x0f_max_populationB\r\n" +
+	"\v_landlocked\"\xaf\x06\n" +
+	"\aCountry\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1e\n" +
+	"\n" +
+	"population\x18\x02 \x01(\x03R\n" +
+	"population\x12\x18\n" +
+	"\acapital\x18\x03 \x01(\tR\acapital\x12\x1a\n" +
+	"\bcurrency\x18\x04 \x01(\tR\bcurrency\x12\x12\n" +
+	"\x04cca2\x18\x05 \x01(\tR\x04cca2\x12\x12\n" +
+	"\x04cca3\x18\x06 \x01(\tR\x04cca3\x12\x12\n" +
+	"\x04ccn3\x18\a \x01(\tR\x04ccn3\x12\x12\n" +
+	"\x04cioc\x18\b \x01(\tR\x04cioc\x12\x1a\n" +
+	"\bcapitals\x18\t \x03(\tR\bcapitals\x12D\n" +
+	"\n" +
+	"currencies\x18\n" +
+	" \x03(\v2$.searchsvc.countrysearch.v1.CurrencyR\n" +
+	"currencies\x12\x16\n" +
+	"\x06region\x18\v \x01(\tR\x06region\x12\x1c\n" +
+	"\tsubregion\x18\f \x01(\tR\tsubregion\x12B\n" +
+	"\tlanguages\x18\r \x03(\v2$.searchsvc.countrysearch.v1.LanguageR\tlanguages\x12\x18\n" +
+	"\aborders\x18\x0e \x03(\tR\aborders\x12\x16\n" +
+	"\x06latlng\x18\x0f \x03(\x01R\x06latlng\x12\x12\n" +
+	"\x04area\x18\x10 \x01(\x01R\x04area\x12\x1e\n" +
+	"\n" +
+	"landlocked\x18\x11 \x01(\bR\n" +
+	"landlocked\x12\x1c\n" +
+	"\ttimezones\x18\x12 \x03(\tR\ttimezones\x127\n" +
+	"\x05flags\x18\x13 \x01(\v2!.searchsvc.countrysearch.v1.FlagsR\x05flags\x12#\n" +
+	"\rcalling_codes\x18\x14 \x03(\tR\fcallingCodes\x12\x10\n" +
+	"\x03tld\x18\x15 \x03(\tR\x03tld\x12Y\n" +
+	"\ftranslations\x18\x16 \x03(\v25.searchsvc.countrysearch.v1.Country.TranslationsEntryR\ftranslations\x1a?\n" +
+	"\x11TranslationsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"J\n" +
+	"\bCurrency\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06symbol\x18\x03 \x01(\tR\x06symbol\"2\n" +
+	"\bLanguage\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"=\n" +
+	"\x05Flags\x12\x10\n" +
+	"\x03png\x18\x01 \x01(\tR\x03png\x12\x10\n" +
+	"\x03svg\x18\x02 \x01(\tR\x03svg\x12\x10\n" +
+	"\x03alt\x18\x03 \x01(\tR\x03alt2\x88\x03\n" +
+	"\rCountrySearch\x12X\n" +
+	"\x06Search\x12).searchsvc.countrysearch.v1.SearchRequest\x1a#.searchsvc.countrysearch.v1.Country\x12^\n" +
+	"\tGetByCode\x12,.searchsvc.countrysearch.v1.GetByCodeRequest\x1a#.searchsvc.countrysearch.v1.Country\x12e\n" +
+	"\bBatchGet\x12+.searchsvc.countrysearch.v1.BatchGetRequest\x1a,.searchsvc.countrysearch.v1.BatchGetResponse\x12V\n" +
+	"\x04List\x12'.searchsvc.countrysearch.v1.ListRequest\x1a#.searchsvc.countrysearch.v1.Country0\x01BKZIgithub.com/Prasang-money/searchSvc/proto/countrysearch/v1;countrysearchv1b\x06proto3"
+
+var (
+	file_countrysearch_v1_countrysearch_proto_rawDescOnce sync.Once
+	file_countrysearch_v1_countrysearch_proto_rawDescData []byte
+)
+
+func file_countrysearch_v1_countrysearch_proto_rawDescGZIP() []byte {
+	file_countrysearch_v1_countrysearch_proto_rawDescOnce.Do(func() {
+		file_countrysearch_v1_countrysearch_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_countrysearch_v1_countrysearch_proto_rawDesc), len(file_countrysearch_v1_countrysearch_proto_rawDesc)))
+	})
+	return file_countrysearch_v1_countrysearch_proto_rawDescData
+}
+
+var file_countrysearch_v1_countrysearch_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_countrysearch_v1_countrysearch_proto_goTypes = []any{
+	(*SearchRequest)(nil),    // 0: searchsvc.countrysearch.v1.SearchRequest
+	(*GetByCodeRequest)(nil), // 1: searchsvc.countrysearch.v1.GetByCodeRequest
+	(*BatchItem)(nil),        // 2: searchsvc.countrysearch.v1.BatchItem
+	(*BatchGetRequest)(nil),  // 3: searchsvc.countrysearch.v1.BatchGetRequest
+	(*BatchGetResponse)(nil), // 4: searchsvc.countrysearch.v1.BatchGetResponse
+	(*BatchResult)(nil),      // 5: searchsvc.countrysearch.v1.BatchResult
+	(*Error)(nil),            // 6: searchsvc.countrysearch.v1.Error
+	(*ListRequest)(nil),      // 7: searchsvc.countrysearch.v1.ListRequest
+	(*Country)(nil),          // 8: searchsvc.countrysearch.v1.Country
+	(*Currency)(nil),         // 9: searchsvc.countrysearch.v1.Currency
+	(*Language)(nil),         // 10: searchsvc.countrysearch.v1.Language
+	(*Flags)(nil),            // 11: searchsvc.countrysearch.v1.Flags
+	nil,                      // 12: searchsvc.countrysearch.v1.Country.TranslationsEntry
+}
+var file_countrysearch_v1_countrysearch_proto_depIdxs = []int32{
+	2,  // 0: searchsvc.countrysearch.v1.BatchGetRequest.items:type_name -> searchsvc.countrysearch.v1.BatchItem
+	5,  // 1: searchsvc.countrysearch.v1.BatchGetResponse.results:type_name -> searchsvc.countrysearch.v1.BatchResult
+	2,  // 2: searchsvc.countrysearch.v1.BatchResult.item:type_name -> searchsvc.countrysearch.v1.BatchItem
+	8,  // 3: searchsvc.countrysearch.v1.BatchResult.country:type_name -> searchsvc.countrysearch.v1.Country
+	6,  // 4: searchsvc.countrysearch.v1.BatchResult.error:type_name -> searchsvc.countrysearch.v1.Error
+	9,  // 5: searchsvc.countrysearch.v1.Country.currencies:type_name -> searchsvc.countrysearch.v1.Currency
+	10, // 6: searchsvc.countrysearch.v1.Country.languages:type_name -> searchsvc.countrysearch.v1.Language
+	11, // 7: searchsvc.countrysearch.v1.Country.flags:type_name -> searchsvc.countrysearch.v1.Flags
+	12, // 8: searchsvc.countrysearch.v1.Country.translations:type_name -> searchsvc.countrysearch.v1.Country.TranslationsEntry
+	0,  // 9: searchsvc.countrysearch.v1.CountrySearch.Search:input_type -> searchsvc.countrysearch.v1.SearchRequest
+	1,  // 10: searchsvc.countrysearch.v1.CountrySearch.GetByCode:input_type -> searchsvc.countrysearch.v1.GetByCodeRequest
+	3,  // 11: searchsvc.countrysearch.v1.CountrySearch.BatchGet:input_type -> searchsvc.countrysearch.v1.BatchGetRequest
+	7,  // 12: searchsvc.countrysearch.v1.CountrySearch.List:input_type -> searchsvc.countrysearch.v1.ListRequest
+	8,  // 13: searchsvc.countrysearch.v1.CountrySearch.Search:output_type -> searchsvc.countrysearch.v1.Country
+	8,  // 14: searchsvc.countrysearch.v1.CountrySearch.GetByCode:output_type -> searchsvc.countrysearch.v1.Country
+	4,  // 15: searchsvc.countrysearch.v1.CountrySearch.BatchGet:output_type -> searchsvc.countrysearch.v1.BatchGetResponse
+	8,  // 16: searchsvc.countrysearch.v1.CountrySearch.List:output_type -> searchsvc.countrysearch.v1.Country
+	13, // [13:17] is the sub-list for method output_type
+	9,  // [9:13] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_countrysearch_v1_countrysearch_proto_init() }
+func file_countrysearch_v1_countrysearch_proto_init() {
+	if File_countrysearch_v1_countrysearch_proto != nil {
+		return
+	}
+	file_countrysearch_v1_countrysearch_proto_msgTypes[2].OneofWrappers = []any{
+		(*BatchItem_Name)(nil),
+		(*BatchItem_Code)(nil),
+	}
+	file_countrysearch_v1_countrysearch_proto_msgTypes[5].OneofWrappers = []any{
+		(*BatchResult_Country)(nil),
+		(*BatchResult_Error)(nil),
+	}
+	file_countrysearch_v1_countrysearch_proto_msgTypes[7].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_countrysearch_v1_countrysearch_proto_rawDesc), len(file_countrysearch_v1_countrysearch_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_countrysearch_v1_countrysearch_proto_goTypes,
+		DependencyIndexes: file_countrysearch_v1_countrysearch_proto_depIdxs,
+		MessageInfos:      file_countrysearch_v1_countrysearch_proto_msgTypes,
+	}.Build()
+	File_countrysearch_v1_countrysearch_proto = out.File
+	file_countrysearch_v1_countrysearch_proto_goTypes = nil
+	file_countrysearch_v1_countrysearch_proto_depIdxs = nil
+}
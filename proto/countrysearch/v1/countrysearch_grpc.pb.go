@@ -0,0 +1,257 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: countrysearch/v1/countrysearch.proto
+
+package countrysearchv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CountrySearch_Search_FullMethodName    = "/searchsvc.countrysearch.v1.CountrySearch/Search"
+	CountrySearch_GetByCode_FullMethodName = "/searchsvc.countrysearch.v1.CountrySearch/GetByCode"
+	CountrySearch_BatchGet_FullMethodName  = "/searchsvc.countrysearch.v1.CountrySearch/BatchGet"
+	CountrySearch_List_FullMethodName      = "/searchsvc.countrysearch.v1.CountrySearch/List"
+)
+
+// CountrySearchClient is the client API for CountrySearch service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// CountrySearch looks countries up by name or code, in batches, and lists
+// them. It is served by the same service layer as the REST API.
+type CountrySearchClient interface {
+	// Search returns the country with the given common, official, native or
+	// translated name.
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*Country, error)
+	// GetByCode returns the country with the given cca2, cca3, ccn3 or cioc
+	// code.
+	GetByCode(ctx context.Context, in *GetByCodeRequest, opts ...grpc.CallOption) (*Country, error)
+	// BatchGet looks up many countries by name or code at once.
+	BatchGet(ctx context.Context, in *BatchGetRequest, opts ...grpc.CallOption) (*BatchGetResponse, error)
+	// List streams the countries matching a query, one message per country.
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Country], error)
+}
+
+type countrySearchClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCountrySearchClient(cc grpc.ClientConnInterface) CountrySearchClient {
+	return &countrySearchClient{cc}
+}
+
+func (c *countrySearchClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*Country, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Country)
+	err := c.cc.Invoke(ctx, CountrySearch_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *countrySearchClient) GetByCode(ctx context.Context, in *GetByCodeRequest, opts ...grpc.CallOption) (*Country, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Country)
+	err := c.cc.Invoke(ctx, CountrySearch_GetByCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *countrySearchClient) BatchGet(ctx context.Context, in *BatchGetRequest, opts ...grpc.CallOption) (*BatchGetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGetResponse)
+	err := c.cc.Invoke(ctx, CountrySearch_BatchGet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *countrySearchClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Country], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CountrySearch_ServiceDesc.Streams[0], CountrySearch_List_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListRequest, Country]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CountrySearch_ListClient = grpc.ServerStreamingClient[Country]
+
+// CountrySearchServer is the server API for CountrySearch service.
+// All implementations must embed UnimplementedCountrySearchServer
+// for forward compatibility.
+//
+// CountrySearch looks countries up by name or code, in batches, and lists
+// them. It is served by the same service layer as the REST API.
+type CountrySearchServer interface {
+	// Search returns the country with the given common, official, native or
+	// translated name.
+	Search(context.Context, *SearchRequest) (*Country, error)
+	// GetByCode returns the country with the given cca2, cca3, ccn3 or cioc
+	// code.
+	GetByCode(context.Context, *GetByCodeRequest) (*Country, error)
+	// BatchGet looks up many countries by name or code at once.
+	BatchGet(context.Context, *BatchGetRequest) (*BatchGetResponse, error)
+	// List streams the countries matching a query, one message per country.
+	List(*ListRequest, grpc.ServerStreamingServer[Country]) error
+	mustEmbedUnimplementedCountrySearchServer()
+}
+
+// UnimplementedCountrySearchServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCountrySearchServer struct{}
+
+func (UnimplementedCountrySearchServer) Search(context.Context, *SearchRequest) (*Country, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedCountrySearchServer) GetByCode(context.Context, *GetByCodeRequest) (*Country, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetByCode not implemented")
+}
+func (UnimplementedCountrySearchServer) BatchGet(context.Context, *BatchGetRequest) (*BatchGetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGet not implemented")
+}
+func (UnimplementedCountrySearchServer) List(*ListRequest, grpc.ServerStreamingServer[Country]) error {
+	return status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedCountrySearchServer) mustEmbedUnimplementedCountrySearchServer() {}
+func (UnimplementedCountrySearchServer) testEmbeddedByValue()                       {}
+
+// UnsafeCountrySearchServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CountrySearchServer will
+// result in compilation errors.
+type UnsafeCountrySearchServer interface {
+	mustEmbedUnimplementedCountrySearchServer()
+}
+
+func RegisterCountrySearchServer(s grpc.ServiceRegistrar, srv CountrySearchServer) {
+	// If the following call pancis, it indicates UnimplementedCountrySearchServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CountrySearch_ServiceDesc, srv)
+}
+
+func _CountrySearch_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CountrySearchServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CountrySearch_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CountrySearchServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CountrySearch_GetByCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetByCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CountrySearchServer).GetByCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CountrySearch_GetByCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CountrySearchServer).GetByCode(ctx, req.(*GetByCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CountrySearch_BatchGet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CountrySearchServer).BatchGet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CountrySearch_BatchGet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CountrySearchServer).BatchGet(ctx, req.(*BatchGetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CountrySearch_List_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CountrySearchServer).List(m, &grpc.GenericServerStream[ListRequest, Country]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CountrySearch_ListServer = grpc.ServerStreamingServer[Country]
+
+// CountrySearch_ServiceDesc is the grpc.ServiceDesc for CountrySearch service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CountrySearch_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "searchsvc.countrysearch.v1.CountrySearch",
+	HandlerType: (*CountrySearchServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Search",
+			Handler:    _CountrySearch_Search_Handler,
+		},
+		{
+			MethodName: "GetByCode",
+			Handler:    _CountrySearch_GetByCode_Handler,
+		},
+		{
+			MethodName: "BatchGet",
+			Handler:    _CountrySearch_BatchGet_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "List",
+			Handler:       _CountrySearch_List_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "countrysearch/v1/countrysearch.proto",
+}
@@ -0,0 +1,5 @@
+// Package countrysearchv1 holds the generated code of the CountrySearch gRPC
+// service.
+package countrysearchv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative countrysearch/v1/countrysearch.proto
@@ -1,21 +1,95 @@
 package route
 
 import (
-	"github.com/Prasang-money/searchSvc/cache"
+	"github.com/Prasang-money/searchSvc/gql"
 	"github.com/Prasang-money/searchSvc/handler"
+	"github.com/Prasang-money/searchSvc/openapi"
 	"github.com/Prasang-money/searchSvc/service"
 	"github.com/gin-gonic/gin"
 )
 
-func GetRoute() *gin.Engine {
+// apiGroups are the prefixes the country endpoints are served under. The
+// unversioned paths predate versioning and stay v1.
+var apiGroups = []openapi.Group{
+	{Prefix: "/api/v2", Version: handler.V2},
+	{Prefix: "/api/v1", Version: handler.V1},
+	{Prefix: "/api", Version: handler.V1},
+}
+
+// Option configures the router.
+type Option func(*options)
 
-	router := gin.Default()
-	cache := cache.NewCache(1000)
-	service := service.NewService(cache)
+type options struct {
+	adminToken string
+}
+
+// WithAdminToken serves the admin endpoints, authenticated with token as a
+// bearer token. They are only served by services implementing
+// service.Admin.
+func WithAdminToken(token string) Option {
+	return func(o *options) {
+		o.adminToken = token
+	}
+}
+
+func GetRoute(service service.ServiceInterface, opts ...Option) *gin.Engine {
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	router := gin.New()
 	handler := handler.NewHandler(service)
 
+	router.Use(gin.Logger(), handler.RequestID(), handler.Compress(), handler.Recovery())
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(handler.NoRoute())
+	router.NoMethod(handler.NoMethod())
+
 	router.GET("/health", handler.HealthCheck())
-	router.GET("/api/countries/search", handler.SearchHandler())
+
+	for _, g := range apiGroups {
+		countryRoutes(router.Group(g.Prefix, handler.Version(g.Version, g.Prefix)), handler)
+	}
+
+	graphql := gql.NewHandler(service)
+	router.GET("/graphql", graphql.Query())
+	router.POST("/graphql", graphql.Query())
+
+	if o.adminToken != "" {
+		adminRoutes(router, service, o.adminToken)
+	}
+
+	docs := openapi.NewHandler(apiGroups)
+	router.GET("/openapi.json", docs.Spec())
+	router.GET("/docs/*filepath", docs.UI(handler.NoRoute()))
 
 	return router
 }
+
+// countryRoutes registers the country endpoints of a version of the API.
+func countryRoutes(api *gin.RouterGroup, handler *handler.Handler) {
+	api.GET("/countries", handler.ListHandler())
+	api.GET("/countries/search", handler.SearchHandler())
+	api.GET("/countries/by-code", handler.SearchByCodeHandler())
+	api.GET("/countries/by-capital", handler.SearchByCapitalHandler())
+	api.GET("/countries/by-currency", handler.SearchByCurrencyHandler())
+	api.GET("/countries/fuzzy", handler.FuzzySearchHandler())
+	api.GET("/countries/suggest", handler.SuggestHandler())
+	api.POST("/countries/batch", handler.BatchHandler())
+	api.GET("/countries/export", handler.ExportHandler())
+}
+
+// adminRoutes registers the admin endpoints when svc supports them.
+func adminRoutes(router *gin.Engine, svc service.ServiceInterface, token string) {
+	admin, ok := svc.(service.Admin)
+	if !ok {
+		return
+	}
+	handler := handler.NewAdminHandler(admin, token)
+	group := router.Group("/admin", handler.Authorize())
+	group.GET("/cache", handler.CacheStatsHandler())
+	group.DELETE("/cache", handler.PurgeCacheHandler())
+	group.POST("/cache/warm", handler.WarmCacheHandler())
+}
@@ -0,0 +1,378 @@
+package route
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Prasang-money/searchSvc/internal/servicetest"
+	"github.com/Prasang-money/searchSvc/models"
+	"github.com/Prasang-money/searchSvc/openapi"
+	"github.com/Prasang-money/searchSvc/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// undocumented are the routes left out of the OpenAPI document on purpose.
+var undocumented = map[string]bool{
+	"GET /docs/*filepath": true,
+}
+
+// newService returns a mock service knowing Germany only.
+func newService() *servicetest.Mock {
+	germany := servicetest.Germany
+	m := new(servicetest.Mock)
+	m.Freshness = service.Freshness{Modified: time.Now().Add(-time.Hour), Expires: time.Now().Add(time.Hour)}
+	for _, method := range []string{"SearchCountries", "SearchByCode", "SearchByCapital"} {
+		for _, query := range []string{"Germany", "DE", "Berlin"} {
+			m.On(method, query).Return(&germany, nil)
+		}
+	}
+	m.On("SearchCountries", "Atlantis").Return(nil, &service.NotFoundError{Query: "Atlantis", Suggestions: []string{"Germany"}})
+	m.On("SearchByCode", "XX").Return(nil, &service.NotFoundError{Query: "XX"})
+	m.On("SearchByCurrency", "EUR").Return([]models.CurrencyMatch{{CountryMetadata: germany, MatchedCurrencies: germany.Currencies}}, nil)
+	m.On("FuzzySearch", "Germny", mock.Anything, mock.Anything).Return([]models.FuzzyMatch{{CountryMetadata: germany, MatchedName: "Germany", Score: 0.92}}, nil)
+	m.On("ListCountries", mock.Anything).Return(&service.Page{
+		Countries:  []models.SearchHit{{CountryMetadata: germany, Score: 3}},
+		Total:      2,
+		NextCursor: "Z2VybWFueQ",
+	}, nil)
+	m.On("BatchLookup", mock.Anything, false).Return([]service.BatchResult{
+		{Item: models.BatchItem{Name: "Germany"}, Country: &germany},
+		{Item: models.BatchItem{Code: "XX"}, Err: &service.NotFoundError{Query: "XX"}},
+	}, nil)
+	m.On("ExportCountries").Return([]models.CountryMetadata{germany}, nil)
+	m.On("Suggest", "Ger", mock.Anything).Return([]models.Suggestion{{Name: "Germany", MatchedName: "Germany", Population: germany.Population}}, nil)
+	m.On("CacheStats").Return(models.CacheStats{Size: 4, Capacity: 2000, TTLSeconds: 21600, Hits: 3, Misses: 1})
+	m.On("PurgeCache").Return(4)
+	m.On("WarmCache").Return(1, nil)
+	return m
+}
+
+// adminToken enables the admin endpoints of the router under test.
+const adminToken = "s3cret"
+
+func setupRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	return GetRoute(newService(), WithAdminToken(adminToken))
+}
+
+func TestRoutesMatchSpec(t *testing.T) {
+	router := setupRouter()
+	doc := openapi.Build(apiGroups)
+
+	routes := make(map[string]bool)
+	for _, r := range router.Routes() {
+		key := r.Method + " " + r.Path
+		if !undocumented[key] {
+			routes[key] = true
+		}
+	}
+	documented := make(map[string]bool)
+	for path, item := range doc.Paths {
+		for method := range item.Operations() {
+			documented[method+" "+path] = true
+		}
+	}
+
+	for key := range routes {
+		assert.True(t, documented[key], "route %s is missing from the OpenAPI document", key)
+	}
+	for key := range documented {
+		assert.True(t, routes[key], "the OpenAPI document describes %s, which is not routed", key)
+	}
+}
+
+func TestAdminRoutes_RequireToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := GetRoute(newService())
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/admin/cache", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	router.ServeHTTP(w, req)
+
+	// without a token the admin endpoints are not served at all
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSpec_OperationIDsAndRefs(t *testing.T) {
+	doc := openapi.Build(apiGroups)
+
+	ids := make(map[string]string)
+	for path, item := range doc.Paths {
+		for method, op := range item.Operations() {
+			key := method + " " + path
+			if other, ok := ids[op.OperationID]; ok {
+				t.Errorf("%s and %s share the operation ID %s", key, other, op.OperationID)
+			}
+			ids[op.OperationID] = key
+		}
+	}
+
+	// every reference must resolve
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+	var raw any
+	require.NoError(t, json.Unmarshal(data, &raw))
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if ref, ok := v["$ref"].(string); ok {
+				name, found := strings.CutPrefix(ref, openapi.RefPrefix)
+				assert.True(t, found, "unexpected reference %s", ref)
+				assert.Contains(t, doc.Components.Schemas, name, "reference %s does not resolve", ref)
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(raw)
+}
+
+func TestSpec_Served(t *testing.T) {
+	router := setupRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/openapi.json", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	want, err := json.Marshal(openapi.Build(apiGroups))
+	require.NoError(t, err)
+	assert.JSONEq(t, string(want), w.Body.String())
+
+	tests := []struct {
+		path        string
+		wantCode    int
+		wantContent string
+	}{
+		{"/docs/", http.StatusOK, "swagger-ui"},
+		{"/docs/initializer.js", http.StatusOK, "../openapi.json"},
+		{"/docs/swagger-ui-bundle.js", http.StatusOK, "SwaggerUIBundle"},
+		{"/docs/missing.js", http.StatusNotFound, "route_not_found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantContent)
+		})
+	}
+}
+
+// TestResponsesMatchSpec sends requests through the router and checks the
+// status, headers and body of each response against the operation
+// documenting it. Bodies may not carry undocumented properties.
+func TestResponsesMatchSpec(t *testing.T) {
+	router := setupRouter()
+	doc := openapi.Build(apiGroups)
+
+	tests := []struct {
+		method   string
+		target   string
+		body     string
+		wantCode int
+	}{
+		{"GET", "/health", "", http.StatusOK},
+		{"GET", "/api/countries/search?name=Germany", "", http.StatusOK},
+		{"GET", "/api/v1/countries/search?name=Germany", "", http.StatusOK},
+		{"GET", "/api/v2/countries/search?name=Germany", "", http.StatusOK},
+		{"GET", "/api/v2/countries/search?name=Germany&fields=name,region,flags", "", http.StatusOK},
+		{"GET", "/api/v2/countries/search?name=Atlantis", "", http.StatusNotFound},
+		{"GET", "/api/v1/countries/search", "", http.StatusBadRequest},
+		{"GET", "/api/v2/countries/by-code?code=DE", "", http.StatusOK},
+		{"GET", "/api/v2/countries/by-capital?capital=Berlin", "", http.StatusOK},
+		{"GET", "/api/v2/countries/by-currency?currency=EUR", "", http.StatusOK},
+		{"GET", "/api/v2/countries/fuzzy?q=Germny", "", http.StatusOK},
+		{"GET", "/api/v1/countries/fuzzy?q=Germny", "", http.StatusOK},
+		{"GET", "/api/v2/countries/suggest?q=Ger", "", http.StatusOK},
+		{"GET", "/api/v2/countries?q=german&limit=1", "", http.StatusOK},
+		{"GET", "/api/v2/countries?limit=100", "", http.StatusBadRequest},
+		{"POST", "/api/v2/countries/batch", `{"items":[{"name":"Germany"},{"code":"XX"}]}`, http.StatusOK},
+		{"GET", "/api/v2/countries/export?format=ndjson", "", http.StatusOK},
+		{"GET", "/api/v2/countries/export?format=csv", "", http.StatusOK},
+		{"GET", `/graphql?query={country(code:"DE"){name}}`, "", http.StatusOK},
+		{"POST", "/graphql", `{"query":"{ country(code: \"XX\") { name } }"}`, http.StatusOK},
+		{"GET", "/openapi.json", "", http.StatusOK},
+		{"GET", "/admin/cache", "", http.StatusOK},
+		{"DELETE", "/admin/cache", "", http.StatusOK},
+		{"POST", "/admin/cache/warm", "", http.StatusOK},
+		{"POST", "/admin/cache/warm?unauthorized", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			if tt.wantCode != http.StatusUnauthorized {
+				req.Header.Set("Authorization", "Bearer "+adminToken)
+			}
+			router.ServeHTTP(w, req)
+			require.Equal(t, tt.wantCode, w.Code, w.Body.String())
+
+			item, ok := doc.Paths[req.URL.Path]
+			require.True(t, ok, "%s is not documented", req.URL.Path)
+			op := item.Operations()[tt.method]
+			require.NotNil(t, op, "%s %s is not documented", tt.method, req.URL.Path)
+			resp, ok := op.Responses[strconv.Itoa(w.Code)]
+			require.True(t, ok, "status %d of %s is not documented", w.Code, op.OperationID)
+
+			for name := range resp.Headers {
+				assert.NotEmpty(t, w.Header().Get(name), "documented header %s is missing", name)
+			}
+
+			mediaType, _, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+			require.NoError(t, err)
+			content, ok := resp.Content[mediaType]
+			require.True(t, ok, "%s responses of %s are not documented", mediaType, op.OperationID)
+			for _, err := range validateBody(doc, mediaType, content.Schema, w.Body.Bytes()) {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// validateBody checks a response body against its schema. NDJSON bodies are
+// checked line by line; other non-JSON bodies are only checked to exist.
+func validateBody(doc *openapi.Document, mediaType string, schema *openapi.Schema, body []byte) []string {
+	var docs [][]byte
+	switch {
+	case mediaType == "application/x-ndjson":
+		docs = bytes.Split(bytes.TrimSpace(body), []byte("\n"))
+	case strings.HasSuffix(mediaType, "json"):
+		docs = [][]byte{body}
+	default:
+		if len(body) == 0 {
+			return []string{"empty body"}
+		}
+		return nil
+	}
+
+	var errs []string
+	for _, data := range docs {
+		var v any
+		if err := json.Unmarshal(data, &v); err != nil {
+			errs = append(errs, fmt.Sprintf("decoding body: %v", err))
+			continue
+		}
+		errs = append(errs, validate(doc, schema, v, "body")...)
+	}
+	return errs
+}
+
+// validate checks v, decoded from JSON, against schema. Objects with
+// documented properties may only have those, unless additionalProperties
+// allows more.
+func validate(doc *openapi.Document, schema *openapi.Schema, v any, at string) []string {
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, openapi.RefPrefix)
+		resolved, ok := doc.Components.Schemas[name]
+		if !ok {
+			return []string{fmt.Sprintf("%s: reference %s does not resolve", at, schema.Ref)}
+		}
+		return validate(doc, resolved, v, at)
+	}
+
+	var errs []string
+	fail := func(format string, args ...any) []string {
+		return append(errs, at+": "+fmt.Sprintf(format, args...))
+	}
+	switch schema.Type {
+	case "":
+		return nil
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return fail("want an object, got %T", v)
+		}
+		for _, name := range schema.Required {
+			if _, ok := obj[name]; !ok {
+				errs = fail("required property %q is missing", name)
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			prop, ok := schema.Properties[key]
+			if !ok {
+				prop = schema.AdditionalProperties
+			}
+			if prop == nil {
+				if schema.Properties != nil {
+					errs = fail("property %q is not documented", key)
+				}
+				continue
+			}
+			errs = append(errs, validate(doc, prop, obj[key], at+"."+key)...)
+		}
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			return fail("want an array, got %T", v)
+		}
+		for i, elem := range arr {
+			errs = append(errs, validate(doc, schema.Items, elem, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+	case "string":
+		s, ok := v.(string)
+		if !ok {
+			return fail("want a string, got %T", v)
+		}
+		if len(schema.Enum) > 0 && !contains(schema.Enum, s) {
+			return fail("%q is not one of %v", s, schema.Enum)
+		}
+	case "integer":
+		n, ok := v.(float64)
+		if !ok || n != math.Trunc(n) {
+			return fail("want an integer, got %v", v)
+		}
+	case "number":
+		if _, ok := v.(float64); !ok {
+			return fail("want a number, got %T", v)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fail("want a boolean, got %T", v)
+		}
+	default:
+		return fail("unknown schema type %q", schema.Type)
+	}
+	return errs
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
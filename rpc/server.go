@@ -0,0 +1,211 @@
+// Package rpc serves the CountrySearch gRPC API on top of the same service
+// layer as the REST API.
+package rpc
+
+import (
+	"context"
+	"log"
+
+	"github.com/Prasang-money/searchSvc/models"
+	countrysearchv1 "github.com/Prasang-money/searchSvc/proto/countrysearch/v1"
+	"github.com/Prasang-money/searchSvc/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Server implements the CountrySearch gRPC service.
+type Server struct {
+	countrysearchv1.UnimplementedCountrySearchServer
+	service service.ServiceInterface
+}
+
+func NewServer(svc service.ServiceInterface) *Server {
+	return &Server{
+		service: svc,
+	}
+}
+
+// NewGRPCServer returns a gRPC server with the CountrySearch service, health
+// checking and reflection registered.
+func NewGRPCServer(svc service.ServiceInterface, opts ...grpc.ServerOption) *grpc.Server {
+	server := grpc.NewServer(opts...)
+	countrysearchv1.RegisterCountrySearchServer(server, NewServer(svc))
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(countrysearchv1.CountrySearch_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	reflection.Register(server)
+	return server
+}
+
+func (s *Server) Search(ctx context.Context, req *countrysearchv1.SearchRequest) (*countrysearchv1.Country, error) {
+	resp, err := s.service.SearchCountries(req.GetName(), options(req.GetFields())...)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return project(toProto(*resp), req.GetFields()), nil
+}
+
+func (s *Server) GetByCode(ctx context.Context, req *countrysearchv1.GetByCodeRequest) (*countrysearchv1.Country, error) {
+	resp, err := s.service.SearchByCode(req.GetCode(), options(req.GetFields())...)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return project(toProto(*resp), req.GetFields()), nil
+}
+
+func (s *Server) BatchGet(ctx context.Context, req *countrysearchv1.BatchGetRequest) (*countrysearchv1.BatchGetResponse, error) {
+	items := make([]models.BatchItem, len(req.GetItems()))
+	for i, item := range req.GetItems() {
+		items[i] = models.BatchItem{Name: item.GetName(), Code: item.GetCode()}
+	}
+
+	results, err := s.service.BatchLookup(items, req.GetAtomic(), options(req.GetFields())...)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	resp := &countrysearchv1.BatchGetResponse{Results: make([]*countrysearchv1.BatchResult, len(results))}
+	for i, r := range results {
+		result := &countrysearchv1.BatchResult{Item: req.GetItems()[i]}
+		if r.Err != nil {
+			st := status.Convert(grpcError(r.Err))
+			result.Result = &countrysearchv1.BatchResult_Error{Error: &countrysearchv1.Error{
+				Code:    int32(st.Code()),
+				Message: st.Message(),
+			}}
+		} else {
+			result.Result = &countrysearchv1.BatchResult_Country{Country: project(toProto(*r.Country), req.GetFields())}
+		}
+		resp.Results[i] = result
+	}
+	return resp, nil
+}
+
+func (s *Server) List(req *countrysearchv1.ListRequest, stream grpc.ServerStreamingServer[countrysearchv1.Country]) error {
+	query := service.ListQuery{
+		Query: req.GetQuery(),
+		Sort:  req.GetSort(),
+		Filter: service.Filter{
+			Region:   req.GetRegion(),
+			Language: req.GetLanguage(),
+			Currency: req.GetCurrency(),
+		},
+	}
+	if req.MinPopulation != nil {
+		minPopulation := int(req.GetMinPopulation())
+		query.Filter.MinPopulation = &minPopulation
+	}
+	if req.MaxPopulation != nil {
+		maxPopulation := int(req.GetMaxPopulation())
+		query.Filter.MaxPopulation = &maxPopulation
+	}
+	if req.Landlocked != nil {
+		landlocked := req.GetLandlocked()
+		query.Filter.Landlocked = &landlocked
+	}
+
+	// Without a limit, the whole result is one page
+	page, err := s.service.ListCountries(query)
+	if err != nil {
+		return grpcError(err)
+	}
+	for _, hit := range page.Countries {
+		if err := stream.Send(toProto(hit.CountryMetadata)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// grpcCodes maps the codes of service errors to gRPC status codes. Clients
+// retry Unavailable on their own, so it is kept for an unreachable upstream:
+// retrying an upstream answering bad data would only add load.
+var grpcCodes = map[string]codes.Code{
+	service.CodeNotFound:            codes.NotFound,
+	service.CodeInvalidInput:        codes.InvalidArgument,
+	service.CodeUpstreamUnavailable: codes.Unavailable,
+	service.CodeUpstreamBadResponse: codes.Internal,
+	service.CodeTimeout:             codes.DeadlineExceeded,
+	service.CodeInternal:            codes.Internal,
+}
+
+// grpcError maps a service error to a gRPC status error. Internal and
+// upstream errors get a generic message; their cause is logged.
+func grpcError(err error) error {
+	message, redacted := service.PublicMessage(err)
+	if redacted {
+		log.Printf("countrysearch: %v", err)
+	}
+	return status.Error(grpcCodes[service.ErrorCode(err)], message)
+}
+
+func options(fields []string) []service.Option {
+	if len(fields) == 0 {
+		return nil
+	}
+	return []service.Option{service.WithFields(fields...)}
+}
+
+// project clears every field of the country but the requested ones, which
+// are named like the fields of the REST model.
+func project(country *countrysearchv1.Country, fields []string) *countrysearchv1.Country {
+	if len(fields) == 0 {
+		return country
+	}
+	wanted := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		wanted[f] = true
+	}
+	m := country.ProtoReflect()
+	var unwanted []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if !wanted[fd.JSONName()] {
+			unwanted = append(unwanted, fd)
+		}
+		return true
+	})
+	for _, fd := range unwanted {
+		m.Clear(fd)
+	}
+	return country
+}
+
+func toProto(meta models.CountryMetadata) *countrysearchv1.Country {
+	country := &countrysearchv1.Country{
+		Name:         meta.Name,
+		Population:   int64(meta.Population),
+		Capital:      meta.Capital,
+		Currency:     meta.Currency,
+		Cca2:         meta.CCA2,
+		Cca3:         meta.CCA3,
+		Ccn3:         meta.CCN3,
+		Cioc:         meta.CIOC,
+		Capitals:     meta.Capitals,
+		Region:       meta.Region,
+		Subregion:    meta.Subregion,
+		Borders:      meta.Borders,
+		Latlng:       meta.LatLng,
+		Area:         meta.Area,
+		Landlocked:   meta.Landlocked,
+		Timezones:    meta.Timezones,
+		CallingCodes: meta.CallingCodes,
+		Tld:          meta.TLD,
+		Translations: meta.Translations,
+	}
+	for _, curr := range meta.Currencies {
+		country.Currencies = append(country.Currencies, &countrysearchv1.Currency{Code: curr.Code, Name: curr.Name, Symbol: curr.Symbol})
+	}
+	for _, lang := range meta.Languages {
+		country.Languages = append(country.Languages, &countrysearchv1.Language{Code: lang.Code, Name: lang.Name})
+	}
+	if meta.Flags != nil {
+		country.Flags = &countrysearchv1.Flags{Png: meta.Flags.PNG, Svg: meta.Flags.SVG, Alt: meta.Flags.Alt}
+	}
+	return country
+}
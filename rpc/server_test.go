@@ -0,0 +1,232 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"testing"
+
+	"github.com/Prasang-money/searchSvc/internal/servicetest"
+	"github.com/Prasang-money/searchSvc/models"
+	countrysearchv1 "github.com/Prasang-money/searchSvc/proto/countrysearch/v1"
+	"github.com/Prasang-money/searchSvc/service"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// dial serves the gRPC API of svc over an in-memory listener and returns a
+// connection to it.
+func dial(t *testing.T, svc service.ServiceInterface) *grpc.ClientConn {
+	listener := bufconn.Listen(1 << 20)
+	server := NewGRPCServer(svc)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dialing bufconn: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestSearch(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	client := countrysearchv1.NewCountrySearchClient(dial(t, mockService))
+
+	mockService.On("SearchCountries", "Germany").Return(&servicetest.Germany, nil)
+	mockService.On("SearchCountries", "Atlantis").Return(nil, fmt.Errorf("%w: Atlantis", service.ErrNotFound))
+
+	resp, err := client.Search(context.Background(), &countrysearchv1.SearchRequest{Name: "Germany"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Germany", resp.GetName())
+	assert.Equal(t, []string{"Berlin"}, resp.GetCapitals())
+	assert.Equal(t, "EUR", resp.GetCurrencies()[0].GetCode())
+	assert.Equal(t, servicetest.Germany.Flags.PNG, resp.GetFlags().GetPng())
+
+	// Fields project the response
+	resp, err = client.Search(context.Background(), &countrysearchv1.SearchRequest{Name: "Germany", Fields: []string{"name", "population"}})
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(&countrysearchv1.Country{Name: "Germany", Population: 83240525}, resp), resp.String())
+
+	_, err = client.Search(context.Background(), &countrysearchv1.SearchRequest{Name: "Atlantis"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetByCode_ErrorCodes(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	client := countrysearchv1.NewCountrySearchClient(dial(t, mockService))
+
+	tests := []struct {
+		code string
+		err  error
+		want codes.Code
+	}{
+		{"X1", &service.ValidationError{Field: "code", Reason: "is invalid"}, codes.InvalidArgument},
+		{"XX", fmt.Errorf("%w: XX", service.ErrNotFound), codes.NotFound},
+		{"DE", fmt.Errorf("%w: down", service.ErrUpstreamUnavailable), codes.Unavailable},
+		{"FR", fmt.Errorf("%w: bad", service.ErrUpstreamBadResponse), codes.Internal},
+		{"IT", fmt.Errorf("%w: slow", service.ErrTimeout), codes.DeadlineExceeded},
+		{"ES", fmt.Errorf("boom"), codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			mockService.On("SearchByCode", tt.code).Return(nil, tt.err)
+			_, err := client.GetByCode(context.Background(), &countrysearchv1.GetByCodeRequest{Code: tt.code})
+			assert.Equal(t, tt.want, status.Code(err))
+		})
+	}
+}
+
+func TestErrorMessages_Redacted(t *testing.T) {
+	var logs bytes.Buffer
+	out := log.Writer()
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(out) })
+	mockService := new(servicetest.Mock)
+	client := countrysearchv1.NewCountrySearchClient(dial(t, mockService))
+
+	const upstreamURL = "https://restcountries.com/v3.1/alpha/DE"
+	cause := fmt.Errorf("%w: Get %q: dial tcp: connection refused", service.ErrUpstreamUnavailable, upstreamURL)
+	items := []models.BatchItem{{Code: "DE"}}
+	mockService.On("SearchByCode", "DE").Return(nil, cause)
+	mockService.On("BatchLookup", items, false).Return([]service.BatchResult{{Item: items[0], Err: cause}}, nil)
+
+	_, err := client.GetByCode(context.Background(), &countrysearchv1.GetByCodeRequest{Code: "DE"})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, "the country data source is unavailable", status.Convert(err).Message())
+	assert.NotContains(t, status.Convert(err).Message(), upstreamURL)
+
+	resp, err := client.BatchGet(context.Background(), &countrysearchv1.BatchGetRequest{
+		Items: []*countrysearchv1.BatchItem{{Key: &countrysearchv1.BatchItem_Code{Code: "DE"}}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "the country data source is unavailable", resp.GetResults()[0].GetError().GetMessage())
+
+	// The cause is logged on the server
+	assert.Contains(t, logs.String(), upstreamURL)
+
+	// Other errors keep their message
+	mockService.On("SearchByCode", "XX").Return(nil, fmt.Errorf("%w: XX", service.ErrNotFound))
+	_, err = client.GetByCode(context.Background(), &countrysearchv1.GetByCodeRequest{Code: "XX"})
+	assert.Equal(t, "country not found: XX", status.Convert(err).Message())
+}
+
+func TestBatchGet(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	client := countrysearchv1.NewCountrySearchClient(dial(t, mockService))
+
+	items := []models.BatchItem{{Code: "DE"}, {Name: "Atlantis"}}
+	mockService.On("BatchLookup", items, false).Return([]service.BatchResult{
+		{Item: items[0], Country: &servicetest.Germany},
+		{Item: items[1], Err: fmt.Errorf("%w: Atlantis", service.ErrNotFound)},
+	}, nil)
+	mockService.On("BatchLookup", items, true).Return(nil, &service.BatchError{Index: 1, Err: fmt.Errorf("%w: Atlantis", service.ErrNotFound)})
+
+	req := &countrysearchv1.BatchGetRequest{
+		Items: []*countrysearchv1.BatchItem{
+			{Key: &countrysearchv1.BatchItem_Code{Code: "DE"}},
+			{Key: &countrysearchv1.BatchItem_Name{Name: "Atlantis"}},
+		},
+		Fields: []string{"name"},
+	}
+	resp, err := client.BatchGet(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Len(t, resp.GetResults(), 2)
+	assert.Equal(t, "DE", resp.GetResults()[0].GetItem().GetCode())
+	assert.True(t, proto.Equal(&countrysearchv1.Country{Name: "Germany"}, resp.GetResults()[0].GetCountry()))
+	assert.Equal(t, int32(codes.NotFound), resp.GetResults()[1].GetError().GetCode())
+
+	req.Atomic = true
+	_, err = client.BatchGet(context.Background(), req)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	mockService.AssertExpectations(t)
+}
+
+func TestList(t *testing.T) {
+	mockService := new(servicetest.Mock)
+	client := countrysearchv1.NewCountrySearchClient(dial(t, mockService))
+
+	minPopulation, landlocked := 1000000, false
+	query := service.ListQuery{
+		Query:  "german",
+		Sort:   "-population",
+		Filter: service.Filter{Region: "Europe", MinPopulation: &minPopulation, Landlocked: &landlocked},
+	}
+	mockService.On("ListCountries", query).Return(&service.Page{
+		Countries: []models.SearchHit{{CountryMetadata: servicetest.Germany}, {CountryMetadata: servicetest.Austria}},
+		Total:     2,
+	}, nil)
+
+	stream, err := client.List(context.Background(), &countrysearchv1.ListRequest{
+		Query:         "german",
+		Sort:          "-population",
+		Region:        "Europe",
+		MinPopulation: proto.Int64(1000000),
+		Landlocked:    proto.Bool(false),
+	})
+	assert.NoError(t, err)
+	var names []string
+	for {
+		country, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		names = append(names, country.GetName())
+	}
+	assert.Equal(t, []string{"Germany", "Austria"}, names)
+
+	mockService.On("ListCountries", service.ListQuery{Sort: "capital"}).Return(nil, &service.ValidationError{Field: "sort", Reason: "is invalid"})
+	stream, err = client.List(context.Background(), &countrysearchv1.ListRequest{Sort: "capital"})
+	assert.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	mockService.AssertExpectations(t)
+}
+
+func TestHealthAndReflection(t *testing.T) {
+	conn := dial(t, new(servicetest.Mock))
+
+	health := healthpb.NewHealthClient(conn)
+	resp, err := health.Check(context.Background(), &healthpb.HealthCheckRequest{Service: countrysearchv1.CountrySearch_ServiceDesc.ServiceName})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	assert.NoError(t, err)
+	err = stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	assert.NoError(t, err)
+	reply, err := stream.Recv()
+	assert.NoError(t, err)
+	var services []string
+	for _, s := range reply.GetListServicesResponse().GetService() {
+		services = append(services, s.GetName())
+	}
+	assert.Contains(t, services, countrysearchv1.CountrySearch_ServiceDesc.ServiceName)
+	assert.Contains(t, services, "grpc.health.v1.Health")
+}
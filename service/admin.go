@@ -0,0 +1,49 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/Prasang-money/searchSvc/models"
+)
+
+// Admin manages the cache of a service. Services that implement it can be
+// administered over the admin endpoints.
+type Admin interface {
+	CacheStats() models.CacheStats
+	PurgeCache() int
+	WarmCache() (int, error)
+}
+
+// CacheStats reports the size and use of the country cache.
+func (s *Service) CacheStats() models.CacheStats {
+	return s.cache.Stats()
+}
+
+// PurgeCache empties the country cache and returns how many entries it
+// held. Lookups are answered from the local index or upstream until the
+// cache fills again.
+func (s *Service) PurgeCache() int {
+	return s.cache.Purge()
+}
+
+// WarmCache fetches every country from upstream and caches each under its
+// name, codes and capitals, as a lookup would. The local index is rebuilt
+// from the same countries. It returns how many countries were cached; with
+// a cache too small for all of them, the first ones are evicted.
+func (s *Service) WarmCache() (int, error) {
+	s.localIndex.loadMu.Lock()
+	defer s.localIndex.loadMu.Unlock()
+
+	countries, err := fetchAllCountries()
+	if err != nil {
+		return 0, fmt.Errorf("warming cache: %w", err)
+	}
+	s.installIndex(countries)
+
+	l := newLookup(nil)
+	for _, country := range countries {
+		meta := toMetadata(country)
+		s.store(country, &meta, l)
+	}
+	return len(countries), nil
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Prasang-money/searchSvc/models"
+)
+
+// Limits of batch lookups.
+const (
+	// MaxBatchSize is the largest number of items a batch may hold.
+	MaxBatchSize = 500
+
+	// batchParallelism bounds the lookups of a batch running at once, so a
+	// large batch of cache misses does not flood upstream.
+	batchParallelism = 8
+)
+
+// BatchResult is the outcome of one item of a batch lookup: either the
+// country or the error looking it up.
+type BatchResult struct {
+	Item    models.BatchItem
+	Country *models.CountryMetadata
+	Err     error
+}
+
+// BatchError reports the item that failed an all-or-nothing batch. It
+// unwraps to the error of that item.
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("items[%d]: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// BatchLookup looks up every item by name or by code, through the cache, and
+// returns one result per item in the order of items. Misses are looked up
+// concurrently, a few at a time, and repeated items are looked up once.
+//
+// When atomic is set, the batch is all-or-nothing: lookups stop at the first
+// failure, which is returned as a *BatchError, and no results are.
+func (s *Service) BatchLookup(items []models.BatchItem, atomic bool, opts ...Option) ([]BatchResult, error) {
+	if len(items) == 0 {
+		return nil, &ValidationError{Field: "items", Reason: "must not be empty"}
+	}
+	if len(items) > MaxBatchSize {
+		return nil, &ValidationError{Field: "items", Reason: fmt.Sprintf("must hold at most %d items", MaxBatchSize)}
+	}
+	if err := ValidateFields("fields", newLookup(opts).fields); err != nil {
+		return nil, err
+	}
+
+	// Look each distinct item up once
+	first := make(map[models.BatchItem]int, len(items))
+	var distinct []int
+	for i, item := range items {
+		key := batchKey(item)
+		if _, ok := first[key]; !ok {
+			first[key] = i
+			distinct = append(distinct, i)
+		}
+	}
+
+	results := make([]BatchResult, len(items))
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed bool
+	)
+	sem := make(chan struct{}, batchParallelism)
+	for _, i := range distinct {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			// an all-or-nothing batch stops looking up once an item failed
+			mu.Lock()
+			skip := atomic && failed
+			mu.Unlock()
+			if skip {
+				return
+			}
+
+			country, err := s.lookupItem(items[i], opts)
+			results[i] = BatchResult{Item: items[i], Country: country, Err: err}
+			if err != nil {
+				mu.Lock()
+				failed = true
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i, item := range items {
+		if j := first[batchKey(item)]; j != i {
+			results[i] = BatchResult{Item: item, Country: results[j].Country, Err: results[j].Err}
+		}
+	}
+
+	if atomic {
+		for i, r := range results {
+			if r.Err != nil {
+				return nil, &BatchError{Index: i, Err: r.Err}
+			}
+		}
+	}
+	return results, nil
+}
+
+// lookupItem looks an item up by name or by code, whichever it sets.
+func (s *Service) lookupItem(item models.BatchItem, opts []Option) (*models.CountryMetadata, error) {
+	switch {
+	case item.Name != "" && item.Code != "":
+		return nil, &ValidationError{Field: "code", Reason: "must not be set along with name"}
+	case item.Code != "":
+		return s.SearchByCode(item.Code, opts...)
+	case item.Name != "":
+		return s.SearchCountries(item.Name, opts...)
+	default:
+		return nil, &ValidationError{Field: "name", Reason: "is required without code"}
+	}
+}
+
+// batchKey identifies the items that share a lookup. Codes ignore case.
+func batchKey(item models.BatchItem) models.BatchItem {
+	item.Code = strings.ToUpper(item.Code)
+	return item
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/Prasang-money/searchSvc/models"
+)
+
+// Errors returned by the service. Callers should match them with errors.Is,
+// since they are usually wrapped with more context about the failure.
+var (
+	ErrNotFound            = errors.New("country not found")
+	ErrInvalidInput        = errors.New("invalid input")
+	ErrUpstreamUnavailable = errors.New("upstream unavailable")
+	ErrUpstreamBadResponse = errors.New("upstream bad response")
+	ErrTimeout             = errors.New("upstream timeout")
+)
+
+// Codes of the errors above, reported in the problem documents of the REST
+// API and the extensions of GraphQL errors, and mapped to gRPC status codes.
+const (
+	CodeNotFound            = models.CodeNotFound
+	CodeInvalidInput        = models.CodeInvalidInput
+	CodeUpstreamUnavailable = models.CodeUpstreamUnavailable
+	CodeUpstreamBadResponse = models.CodeUpstreamBadResponse
+	CodeTimeout             = models.CodeTimeout
+	CodeInternal            = models.CodeInternal
+)
+
+// errorCodes pairs the errors of the service with their codes.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrNotFound, CodeNotFound},
+	{ErrInvalidInput, CodeInvalidInput},
+	{ErrUpstreamUnavailable, CodeUpstreamUnavailable},
+	{ErrUpstreamBadResponse, CodeUpstreamBadResponse},
+	{ErrTimeout, CodeTimeout},
+}
+
+// ErrorCode returns the code of the service error err wraps, or
+// CodeInternal for any other error.
+func ErrorCode(err error) string {
+	for _, e := range errorCodes {
+		if errors.Is(err, e.err) {
+			return e.code
+		}
+	}
+	return CodeInternal
+}
+
+// publicMessages replace the messages of internal and upstream errors in
+// API responses, since those may name upstream URLs or the errors of
+// connections to them.
+var publicMessages = map[string]string{
+	CodeUpstreamUnavailable: "the country data source is unavailable",
+	CodeUpstreamBadResponse: "the country data source sent an invalid response",
+	CodeTimeout:             "the country data source did not answer in time",
+	CodeInternal:            "internal server error",
+}
+
+// PublicMessage returns the message of err to show API clients. Internal
+// and upstream errors get a generic message, and redacted reports that
+// their cause should be logged instead.
+func PublicMessage(err error) (message string, redacted bool) {
+	if message, ok := publicMessages[ErrorCode(err)]; ok {
+		return message, true
+	}
+	return err.Error(), false
+}
+
+// NotFoundError is returned when no country matches a query. It matches
+// ErrNotFound under errors.Is and carries the names of close matches, if
+// any, as "did you mean" suggestions.
+type NotFoundError struct {
+	Query       string
+	Suggestions []string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrNotFound, e.Query)
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return ErrNotFound
+}
+
+// upstreamError classifies a transport error from the HTTP client as either
+// a timeout or an unavailable upstream.
+func upstreamError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) || os.IsTimeout(err) {
+		return fmt.Errorf("%w: %v", ErrTimeout, err)
+	}
+	return fmt.Errorf("%w: failed to fetch country data: %v", ErrUpstreamUnavailable, err)
+}
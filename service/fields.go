@@ -0,0 +1,134 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Prasang-money/searchSvc/models"
+)
+
+// upstreamFields maps each field of models.CountryMetadata to the upstream
+// fields it is built from.
+var upstreamFields = map[string][]string{
+	"name":         {"name"},
+	"population":   {"population"},
+	"capital":      {"capital"},
+	"currency":     {"currencies"},
+	"cca2":         {"cca2"},
+	"cca3":         {"cca3"},
+	"ccn3":         {"ccn3"},
+	"cioc":         {"cioc"},
+	"capitals":     {"capital"},
+	"currencies":   {"currencies"},
+	"region":       {"region"},
+	"subregion":    {"subregion"},
+	"languages":    {"languages"},
+	"borders":      {"borders"},
+	"latlng":       {"latlng"},
+	"area":         {"area"},
+	"landlocked":   {"landlocked"},
+	"timezones":    {"timezones"},
+	"flags":        {"flags"},
+	"callingCodes": {"idd"},
+	"tld":          {"tld"},
+	"translations": {"translations"},
+}
+
+// keyFields are always requested upstream when a lookup is projected, since
+// the service matches and caches countries on them. Translations are only
+// requested when needed, being most of the payload of a country.
+var keyFields = []string{"name", "cca2", "cca3", "ccn3", "cioc", "capital"}
+
+// Option customizes a single lookup.
+type Option func(*lookup)
+
+type lookup struct {
+	fields    []string
+	lang      string
+	freshness *Freshness
+}
+
+// WithFields restricts a lookup to the given fields of
+// models.CountryMetadata. Only the upstream fields needed to build them are
+// requested, so the returned country may have other fields left empty.
+func WithFields(fields ...string) Option {
+	return func(l *lookup) {
+		l.fields = fields
+	}
+}
+
+// WithLanguage tells a lookup that the name of the country will be
+// localized in lang, one of models.TranslationKeys. A projected lookup then
+// also fetches the translations, unless lang is models.DefaultLanguage.
+func WithLanguage(lang string) Option {
+	return func(l *lookup) {
+		l.lang = lang
+	}
+}
+
+func newLookup(opts []Option) lookup {
+	var l lookup
+	for _, opt := range opts {
+		opt(&l)
+	}
+	if len(l.fields) > 0 && l.lang != "" && l.lang != models.DefaultLanguage {
+		l.fields = append(slices.Clip(l.fields), "translations")
+	}
+	return l
+}
+
+// ValidateFields checks that every requested field exists.
+func ValidateFields(field string, fields []string) error {
+	for _, f := range fields {
+		if _, ok := upstreamFields[f]; !ok {
+			return &ValidationError{Field: field, Reason: fmt.Sprintf("contains unknown field %q", f)}
+		}
+	}
+	return nil
+}
+
+// projection returns the requested fields sorted and deduplicated, so
+// equivalent field lists share cache entries.
+func (l lookup) projection() string {
+	if len(l.fields) == 0 {
+		return ""
+	}
+	return strings.Join(sortedUnique(l.fields), ",")
+}
+
+// key namespaces a cache key by the projection. Unprojected lookups keep the
+// plain key so they are shared with every other lookup.
+func (l lookup) key(base string) string {
+	if p := l.projection(); p != "" {
+		return base + "|fields=" + p
+	}
+	return base
+}
+
+// query returns the upstream query string restricting the response to the
+// projected fields plus extra, or "" when the lookup is not projected.
+func (l lookup) query(extra ...string) string {
+	if len(l.fields) == 0 {
+		return ""
+	}
+	wanted := append(append([]string{}, keyFields...), extra...)
+	for _, f := range l.fields {
+		wanted = append(wanted, upstreamFields[f]...)
+	}
+	return "?fields=" + strings.Join(sortedUnique(wanted), ",")
+}
+
+func sortedUnique(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var result []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
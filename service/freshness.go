@@ -0,0 +1,65 @@
+package service
+
+import (
+	"time"
+
+	"github.com/Prasang-money/searchSvc/cache"
+)
+
+// Freshness tells when a country returned by a lookup was fetched from
+// upstream, and until when the service keeps answering with that copy.
+type Freshness struct {
+	Modified time.Time
+	// Expires is zero when the copy has no known expiry.
+	Expires time.Time
+}
+
+// WithFreshness makes a lookup report the freshness of the country it
+// returns in f.
+func WithFreshness(f *Freshness) Option {
+	return func(l *lookup) {
+		l.freshness = f
+	}
+}
+
+// FreshnessOf returns the Freshness that opts ask to be reported in, or nil.
+// Other implementations of ServiceInterface use it to honor WithFreshness.
+func FreshnessOf(opts ...Option) *Freshness {
+	return newLookup(opts).freshness
+}
+
+// report records the freshness of the returned country, if requested.
+func (l lookup) report(f Freshness) {
+	if l.freshness != nil {
+		*l.freshness = f
+	}
+}
+
+// entryFreshness is the freshness of a cached country.
+func entryFreshness(entry cache.Entry) Freshness {
+	return Freshness{Modified: entry.StoredAt, Expires: entry.ExpiresAt}
+}
+
+// fetchedFreshness is the freshness of a country just fetched from upstream
+// and cached under key. A country the cache could not keep is fresh as of
+// now, with the TTL of the cache.
+func (s *Service) fetchedFreshness(key string) Freshness {
+	if entry, found := s.cache.GetEntry(key); found {
+		return entryFreshness(entry)
+	}
+	f := Freshness{Modified: time.Now()}
+	if ttl := s.cache.TTL(); ttl > 0 {
+		f.Expires = f.Modified.Add(ttl)
+	}
+	return f
+}
+
+// indexFreshness is the freshness of a country answered from the local
+// index, which is kept until the next refresh.
+func (s *Service) indexFreshness() Freshness {
+	f := Freshness{Modified: time.Unix(0, s.localIndex.loadedAt.Load())}
+	if interval := time.Duration(s.localIndex.interval.Load()); interval > 0 {
+		f.Expires = f.Modified.Add(interval)
+	}
+	return f
+}
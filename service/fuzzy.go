@@ -0,0 +1,81 @@
+package service
+
+import (
+	"github.com/Prasang-money/searchSvc/models"
+)
+
+// DefaultFuzzyThreshold is the minimum similarity, from 0 to 1, a name must
+// have to the query to be returned by a fuzzy search. It lets one typo
+// through in names of five letters or more.
+const DefaultFuzzyThreshold = 0.75
+
+// maxSuggestions caps the "did you mean" suggestions of a failed search.
+const maxSuggestions = 3
+
+// FuzzySearch returns the countries whose common, official or alternative
+// names are at least threshold similar to the query, best first, at most
+// limit of them. It is answered from a local index over every country.
+func (s *Service) FuzzySearch(query string, threshold float64, limit int) ([]models.FuzzyMatch, error) {
+	if err := ValidateName("q", query); err != nil {
+		return nil, err
+	}
+	if threshold < 0 || threshold > 1 {
+		return nil, &ValidationError{Field: "threshold", Reason: "must be between 0 and 1"}
+	}
+
+	ix, err := s.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := ix.Fuzzy(query, threshold, limit)
+	result := make([]models.FuzzyMatch, len(matches))
+	for i, m := range matches {
+		result[i] = models.FuzzyMatch{
+			CountryMetadata: m.Country,
+			MatchedName:     m.Name,
+			Score:           m.Score,
+		}
+	}
+	return result, nil
+}
+
+// Suggest returns up to limit countries with a common, official or
+// alternative name, or a word of one, starting with prefix, most populous
+// first. It is answered from the local index, without calling upstream.
+func (s *Service) Suggest(prefix string, limit int) ([]models.Suggestion, error) {
+	if err := ValidateName("q", prefix); err != nil {
+		return nil, err
+	}
+
+	ix, err := s.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := ix.Suggest(prefix, limit)
+	result := make([]models.Suggestion, len(suggestions))
+	for i, sg := range suggestions {
+		result[i] = models.Suggestion{
+			Name:        sg.Country.Name,
+			MatchedName: sg.Name,
+			Population:  sg.Country.Population,
+		}
+	}
+	return result, nil
+}
+
+// notFound builds the error for a query that matched nothing, suggesting
+// close matches when the local index is loaded. It never loads the index
+// itself, so a miss costs no more than the lookup that missed.
+func (s *Service) notFound(query string) error {
+	err := &NotFoundError{Query: query}
+	ix := s.local()
+	if ix == nil {
+		return err
+	}
+	for _, m := range ix.Fuzzy(query, DefaultFuzzyThreshold, maxSuggestions) {
+		err.Suggestions = append(err.Suggestions, m.Country.Name)
+	}
+	return err
+}
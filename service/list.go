@@ -0,0 +1,305 @@
+package service
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Prasang-money/searchSvc/models"
+)
+
+// Keys list queries can be sorted by. Prefixing a key with "-" sorts in
+// descending order.
+const (
+	SortName       = "name"
+	SortPopulation = "population"
+	SortArea       = "area"
+)
+
+// ListQuery selects a page of countries from the local index. Every filter
+// is optional; an empty query lists every country.
+type ListQuery struct {
+	// Query holds full-text search terms. When set, countries are ranked by
+	// relevance unless Sort says otherwise.
+	Query  string
+	Filter Filter
+
+	// Sort is a sort key, optionally prefixed with "-" for descending order.
+	// It defaults to relevance for searches and to name otherwise.
+	Sort string
+
+	// Cursor is the NextCursor of the previous page, empty for the first.
+	// It is only valid with the query, filter and sort of that page.
+	Cursor string
+	Limit  int
+}
+
+// Filter restricts a list query. Nil bounds and empty strings match every
+// country.
+type Filter struct {
+	Region        string
+	MinPopulation *int
+	MaxPopulation *int
+	Language      string
+	Currency      string
+	Landlocked    *bool
+}
+
+// Page is one page of the result of a list query.
+type Page struct {
+	Countries []models.SearchHit
+
+	// Total counts the countries matching the query across all pages.
+	Total int
+
+	// NextCursor fetches the following page. It is empty on the last one.
+	NextCursor string
+}
+
+// ListCountries filters, sorts and paginates the countries of the local
+// index. A cursor holds the sort key and name of the last country of its
+// page, and the next page starts after it, so refreshing the index in
+// between neither repeats nor skips countries that remain listed.
+func (s *Service) ListCountries(q ListQuery) (*Page, error) {
+	sortKey := q.Sort
+	if sortKey == "" && q.Query == "" {
+		sortKey = SortName
+	}
+	after, err := validateListQuery(q, sortKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ix, err := s.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	var hits []models.SearchHit
+	if q.Query != "" {
+		for _, m := range ix.Search(q.Query, 0) {
+			hits = append(hits, models.SearchHit{CountryMetadata: m.Country, Score: m.Score})
+		}
+	} else {
+		for _, country := range ix.All() {
+			hits = append(hits, models.SearchHit{CountryMetadata: country})
+		}
+	}
+
+	matching := hits[:0]
+	for _, hit := range hits {
+		if q.Filter.matches(hit.CountryMetadata) {
+			matching = append(matching, hit)
+		}
+	}
+	sortHits(matching, sortKey)
+
+	page := &Page{Total: len(matching)}
+	start := 0
+	if after != nil {
+		start = sort.Search(len(matching), func(i int) bool {
+			return comparePositions(positionOf(matching[i], sortKey), after.After, sortKey) > 0
+		})
+	}
+	if start >= len(matching) {
+		return page, nil
+	}
+	end := start + q.Limit
+	if q.Limit <= 0 || end >= len(matching) {
+		end = len(matching)
+	} else {
+		page.NextCursor = encodeCursor(cursor{Query: q.fingerprint(sortKey), After: positionOf(matching[end-1], sortKey)})
+	}
+	page.Countries = matching[start:end]
+	return page, nil
+}
+
+// ExportCountries calls fn for every country of the local index, one at a
+// time, so the dataset can be streamed without being copied. It stops at
+// the first error fn returns and returns it.
+func (s *Service) ExportCountries(fn func(models.CountryMetadata) error) error {
+	ix, err := s.loadIndex()
+	if err != nil {
+		return err
+	}
+	return ix.Each(fn)
+}
+
+// validateListQuery checks every parameter of q, sorted by sortKey, and
+// returns its decoded cursor, if any.
+func validateListQuery(q ListQuery, sortKey string) (*cursor, error) {
+	if q.Query != "" {
+		if err := ValidateName("q", q.Query); err != nil {
+			return nil, err
+		}
+	}
+	if q.Filter.Region != "" {
+		if err := ValidateName("region", q.Filter.Region); err != nil {
+			return nil, err
+		}
+	}
+	if q.Filter.Language != "" {
+		if err := ValidateName("language", q.Filter.Language); err != nil {
+			return nil, err
+		}
+	}
+	if q.Filter.Currency != "" {
+		if err := ValidateCurrency("currency", q.Filter.Currency); err != nil {
+			return nil, err
+		}
+	}
+
+	minPop, maxPop := q.Filter.MinPopulation, q.Filter.MaxPopulation
+	if minPop != nil && *minPop < 0 {
+		return nil, &ValidationError{Field: "minPopulation", Reason: "must not be negative"}
+	}
+	if maxPop != nil && *maxPop < 0 {
+		return nil, &ValidationError{Field: "maxPopulation", Reason: "must not be negative"}
+	}
+	if minPop != nil && maxPop != nil && *minPop > *maxPop {
+		return nil, &ValidationError{Field: "maxPopulation", Reason: "must not be less than minPopulation"}
+	}
+
+	if q.Sort != "" {
+		switch strings.TrimPrefix(q.Sort, "-") {
+		case SortName, SortPopulation, SortArea:
+		default:
+			return nil, &ValidationError{Field: "sort", Reason: fmt.Sprintf("must be one of %s, %s or %s, optionally prefixed with -", SortName, SortPopulation, SortArea)}
+		}
+	}
+
+	if q.Cursor == "" {
+		return nil, nil
+	}
+	c, ok := decodeCursor(q.Cursor)
+	if !ok {
+		return nil, &ValidationError{Field: "cursor", Reason: "is not a valid cursor"}
+	}
+	if c.Query != q.fingerprint(sortKey) {
+		return nil, &ValidationError{Field: "cursor", Reason: "belongs to a different query or sort"}
+	}
+	return &c, nil
+}
+
+func (f Filter) matches(c models.CountryMetadata) bool {
+	if f.Region != "" && !strings.EqualFold(c.Region, f.Region) {
+		return false
+	}
+	if f.MinPopulation != nil && c.Population < *f.MinPopulation {
+		return false
+	}
+	if f.MaxPopulation != nil && c.Population > *f.MaxPopulation {
+		return false
+	}
+	if f.Landlocked != nil && c.Landlocked != *f.Landlocked {
+		return false
+	}
+	if f.Language != "" && !speaks(c, f.Language) {
+		return false
+	}
+	if f.Currency != "" && len(matchingCurrencies(c, f.Currency)) == 0 {
+		return false
+	}
+	return true
+}
+
+// speaks reports whether language, an ISO 639-3 code or an English name, is
+// spoken in the country.
+func speaks(c models.CountryMetadata, language string) bool {
+	for _, lang := range c.Languages {
+		if strings.EqualFold(lang.Code, language) || strings.EqualFold(lang.Name, language) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortHits sorts hits by key, or by relevance when key is empty. Ties are
+// broken by name, so pages are stable.
+func sortHits(hits []models.SearchHit, key string) {
+	slices.SortStableFunc(hits, func(a, b models.SearchHit) int {
+		return comparePositions(positionOf(a, key), positionOf(b, key), key)
+	})
+}
+
+// position is where a country sorts in a list: the value of its sort key,
+// if numeric, and its name.
+type position struct {
+	Key  float64 `json:"k,omitempty"`
+	Name string  `json:"n"`
+}
+
+func positionOf(hit models.SearchHit, key string) position {
+	switch strings.TrimPrefix(key, "-") {
+	case "":
+		return position{Key: hit.Score, Name: hit.Name}
+	case SortPopulation:
+		return position{Key: float64(hit.Population), Name: hit.Name}
+	case SortArea:
+		return position{Key: hit.Area, Name: hit.Name}
+	default:
+		return position{Name: hit.Name}
+	}
+}
+
+// comparePositions orders positions by key, descending for relevance and
+// keys prefixed with "-", then by name.
+func comparePositions(a, b position, key string) int {
+	c := cmp.Compare(a.Key, b.Key)
+	if strings.TrimPrefix(key, "-") == SortName {
+		c = strings.Compare(a.Name, b.Name)
+	}
+	if key == "" || strings.HasPrefix(key, "-") {
+		c = -c
+	}
+	if c != 0 {
+		return c
+	}
+	return strings.Compare(a.Name, b.Name)
+}
+
+// cursor is where the next page of a list query starts: after the last
+// country of the previous one. Query fingerprints the query it was issued
+// for, so it is not used with another.
+type cursor struct {
+	Query string   `json:"q"`
+	After position `json:"a"`
+}
+
+// fingerprint identifies the countries q lists and their order, sorted by
+// sortKey, but not the page size.
+func (q ListQuery) fingerprint(sortKey string) string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%q %q %q %s %s %q %q %s", q.Query, sortKey, q.Filter.Region,
+		optional(q.Filter.MinPopulation), optional(q.Filter.MaxPopulation),
+		q.Filter.Language, q.Filter.Currency, optional(q.Filter.Landlocked))
+	return strconv.FormatUint(h.Sum64(), 36)
+}
+
+func optional[T any](p *T) string {
+	if p == nil {
+		return "-"
+	}
+	return fmt.Sprint(*p)
+}
+
+// Cursors are opaque to clients: base64 encoded JSON.
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (cursor, bool) {
+	var c cursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || json.Unmarshal(raw, &c) != nil || c.Query == "" {
+		return cursor{}, false
+	}
+	return c, true
+}
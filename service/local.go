@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Prasang-money/searchSvc/index"
+	"github.com/Prasang-money/searchSvc/models"
+)
+
+// allFieldChunks are the field lists the full country list is fetched with.
+// Upstream caps /all at ten fields per request, so the list is fetched once
+// per chunk and the chunks are merged on cca3.
+var allFieldChunks = []string{
+	"cca3,name,cca2,ccn3,cioc,altSpellings,population,capital,currencies,region",
+	"cca3,subregion,languages,borders,latlng,area,timezones,flags,idd,tld",
+	"cca3,translations,landlocked",
+}
+
+// Weights of the terms countries are found by in full-text search. A hit on
+// the common name ranks highest.
+const (
+	weightCommonName   = 10
+	weightOfficialName = 6
+	weightAltSpelling  = 4
+	weightTranslation  = 4
+	weightCapital      = 4
+	weightLanguage     = 2
+	weightCurrency     = 2
+)
+
+// localIndex holds the index over every country. Readers load it without
+// locking; a rebuild swaps in a new index atomically, so queries never see
+// a partial one.
+type localIndex struct {
+	current atomic.Pointer[index.Index]
+	// loadMu serializes loads so concurrent first uses share one fetch
+	loadMu sync.Mutex
+	// loadedAt is the time the current index was loaded, in Unix
+	// nanoseconds, and interval the refresh interval, if any
+	loadedAt atomic.Int64
+	interval atomic.Int64
+}
+
+// local returns the current local index, or nil when none has been loaded
+// yet. It never blocks.
+func (s *Service) local() *index.Index {
+	return s.localIndex.current.Load()
+}
+
+// loadIndex returns the local index, loading it from upstream if this is
+// the first use. A failed load is retried on the next call.
+func (s *Service) loadIndex() (*index.Index, error) {
+	if ix := s.local(); ix != nil {
+		return ix, nil
+	}
+
+	s.localIndex.loadMu.Lock()
+	defer s.localIndex.loadMu.Unlock()
+	if ix := s.local(); ix != nil {
+		return ix, nil
+	}
+	return s.rebuildIndex()
+}
+
+// loadIndexAsync starts loading the local index in the background, unless
+// it is loaded or already loading, for requests that should not wait for
+// it.
+func (s *Service) loadIndexAsync() {
+	if s.local() != nil || !s.localIndex.loadMu.TryLock() {
+		return
+	}
+	go func() {
+		defer s.localIndex.loadMu.Unlock()
+		if s.local() != nil {
+			return
+		}
+		if _, err := s.rebuildIndex(); err != nil {
+			log.Print(err)
+		}
+	}()
+}
+
+// RefreshIndex reloads every country from upstream and swaps the new index
+// in. On failure the current index is kept.
+func (s *Service) RefreshIndex() error {
+	s.localIndex.loadMu.Lock()
+	defer s.localIndex.loadMu.Unlock()
+	_, err := s.rebuildIndex()
+	return err
+}
+
+// StartIndexRefresh loads the local index in the background and reloads it
+// every interval until ctx is done. Until the first load completes, lookups
+// fall back to the upstream API.
+func (s *Service) StartIndexRefresh(ctx context.Context, interval time.Duration) {
+	s.localIndex.interval.Store(int64(interval))
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := s.RefreshIndex(); err != nil {
+				log.Printf("refreshing country index: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *Service) rebuildIndex() (*index.Index, error) {
+	countries, err := fetchAllCountries()
+	if err != nil {
+		return nil, fmt.Errorf("loading country index: %w", err)
+	}
+	return s.installIndex(countries), nil
+}
+
+// installIndex builds the index of countries and swaps it in.
+func (s *Service) installIndex(countries []models.Country) *index.Index {
+	entries := make([]index.Entry, len(countries))
+	for i, country := range countries {
+		entries[i] = indexEntry(country)
+	}
+	ix := index.New(entries)
+	s.localIndex.loadedAt.Store(time.Now().UnixNano())
+	s.localIndex.current.Store(ix)
+	return ix
+}
+
+// fetchAllCountries fetches every country, one request per field chunk, and
+// merges the chunks into complete countries.
+func fetchAllCountries() ([]models.Country, error) {
+	var countries []*models.Country
+	byCode := make(map[string]*models.Country)
+
+	for i, fields := range allFieldChunks {
+		var chunk []json.RawMessage
+		if err := fetchJSON(allURL+"?fields="+fields, &chunk); err != nil {
+			return nil, err
+		}
+		for _, raw := range chunk {
+			var key struct {
+				CCA3 string `json:"cca3"`
+			}
+			if err := json.Unmarshal(raw, &key); err != nil || key.CCA3 == "" {
+				return nil, fmt.Errorf("%w: country without cca3 code", ErrUpstreamBadResponse)
+			}
+
+			country, ok := byCode[key.CCA3]
+			if !ok {
+				if i > 0 {
+					// only merge into countries the first chunk listed
+					continue
+				}
+				country = &models.Country{}
+				byCode[key.CCA3] = country
+				countries = append(countries, country)
+			}
+			// decoding into an existing struct only sets the fields present
+			if err := json.Unmarshal(raw, country); err != nil {
+				return nil, fmt.Errorf("%w: failed to decode response body: %v", ErrUpstreamBadResponse, err)
+			}
+		}
+	}
+
+	result := make([]models.Country, len(countries))
+	for i, country := range countries {
+		result[i] = *country
+	}
+	return result, nil
+}
+
+// indexEntry builds the index entry of a country with its weighted terms.
+func indexEntry(country models.Country) index.Entry {
+	meta := toMetadata(country)
+	names := append([]string{country.Name.Common, country.Name.Official}, country.AltSpellings...)
+
+	terms := []index.Term{
+		{Text: country.Name.Common, Weight: weightCommonName},
+		{Text: country.Name.Official, Weight: weightOfficialName},
+	}
+	for _, alt := range country.AltSpellings {
+		terms = append(terms, index.Term{Text: alt, Weight: weightAltSpelling})
+	}
+	for _, name := range meta.Translations {
+		terms = append(terms, index.Term{Text: name, Weight: weightTranslation})
+	}
+	for _, capital := range country.Capital {
+		terms = append(terms, index.Term{Text: capital, Weight: weightCapital})
+	}
+	for _, lang := range meta.Languages {
+		terms = append(terms, index.Term{Text: lang.Name, Weight: weightLanguage})
+	}
+	for _, curr := range meta.Currencies {
+		terms = append(terms,
+			index.Term{Text: curr.Code, Weight: weightCurrency},
+			index.Term{Text: curr.Name, Weight: weightCurrency},
+		)
+	}
+
+	return index.Entry{Country: meta, Names: names, Terms: terms}
+}
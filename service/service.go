@@ -2,9 +2,13 @@ package service
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/Prasang-money/searchSvc/cache"
@@ -14,11 +18,42 @@ import (
 // make baseURL a variable so tests can override it
 var baseURL = "https://restcountries.com/v3.1/name/"
 
+// translationURL looks countries up by their name in any language
+var translationURL = "https://restcountries.com/v3.1/translation/"
+
+// alphaURL looks countries up by any of their ISO 3166 or IOC codes
+var alphaURL = "https://restcountries.com/v3.1/alpha/"
+
+// capitalURL looks countries up by capital city
+var capitalURL = "https://restcountries.com/v3.1/capital/"
+
+// currencyURL looks countries up by ISO 4217 currency code
+var currencyURL = "https://restcountries.com/v3.1/currency/"
+
+// allURL lists every country. Upstream requires a field list for it, see
+// allFieldChunks.
+var allURL = "https://restcountries.com/v3.1/all"
+
+// httpClient is shared across requests so connections to the upstream API
+// are pooled. Tests can swap it to shorten the timeout.
+var httpClient = &http.Client{
+	Timeout: 10 * time.Second,
+}
+
 type ServiceInterface interface {
-	SearchCountries(name string) (*models.CountryMetadata, error)
+	SearchCountries(name string, opts ...Option) (*models.CountryMetadata, error)
+	SearchByCode(code string, opts ...Option) (*models.CountryMetadata, error)
+	SearchByCapital(capital string, opts ...Option) (*models.CountryMetadata, error)
+	SearchByCurrency(currency string, opts ...Option) ([]models.CurrencyMatch, error)
+	FuzzySearch(query string, threshold float64, limit int) ([]models.FuzzyMatch, error)
+	ListCountries(q ListQuery) (*Page, error)
+	BatchLookup(items []models.BatchItem, atomic bool, opts ...Option) ([]BatchResult, error)
+	ExportCountries(fn func(models.CountryMetadata) error) error
+	Suggest(prefix string, limit int) ([]models.Suggestion, error)
 }
 type Service struct {
-	cache *cache.Cache
+	cache      *cache.Cache
+	localIndex localIndex
 }
 
 func NewService(cache *cache.Cache) *Service {
@@ -27,63 +62,396 @@ func NewService(cache *cache.Cache) *Service {
 	}
 }
 
-func (s *Service) SearchCountries(name string) (*models.CountryMetadata, error) {
+func (s *Service) SearchCountries(name string, opts ...Option) (*models.CountryMetadata, error) {
+	if err := ValidateName("name", name); err != nil {
+		return nil, err
+	}
+	l := newLookup(opts)
+	if err := ValidateFields("fields", l.fields); err != nil {
+		return nil, err
+	}
+
 	// Check if results are in cache
-	if cachedResults, found := s.cache.Get(name); found {
-		return &cachedResults, nil
+	if cachedResults, found := s.cached(name, l); found {
+		return cachedResults, nil
+
+	}
 
+	// Answer from the local index once it is loaded
+	if ix := s.local(); ix != nil {
+		if meta, ok := ix.ByName(name); ok {
+			l.report(s.indexFreshness())
+			return &meta, nil
+		}
+		return nil, s.notFound(name)
 	}
 
-	// If not found in cache, fetch from REST API
-	url := baseURL + name
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+	// Otherwise fetch from REST API. The name endpoint knows
+	// common, official and native names; localized names such as
+	// "Allemagne" are only found by the translation endpoint, whose
+	// results are matched on their translations.
+	countries, err := fetchCountries(baseURL + url.PathEscape(name) + l.query())
+	if errors.Is(err, ErrNotFound) {
+		countries, err = fetchCountries(translationURL + url.PathEscape(name) + l.query("translations"))
+	}
+	if errors.Is(err, ErrNotFound) {
+		return nil, s.notFound(name)
 	}
-	resp, err := client.Get(url)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch country data: %v", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status code: %d", resp.StatusCode)
+	if country, ok := matchName(countries, name); ok {
+		countryMetaData := toMetadata(country)
+		// Store results in cache before returning
+		s.store(country, &countryMetaData, l)
+		if name != country.Name.Common {
+			s.cache.Set(l.key(name), &countryMetaData)
+		}
+		l.report(s.fetchedFreshness(l.key(country.Name.Common)))
+		return &countryMetaData, nil
 	}
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
+	return nil, s.notFound(name)
+}
+
+// matchName picks the country called name, ignoring case. Common names are
+// preferred, so "Guinea" is not mistaken for "Equatorial Guinea", before
+// any official, native or translated name is considered.
+func matchName(countries []models.Country, name string) (models.Country, bool) {
+	for _, country := range countries {
+		if strings.EqualFold(country.Name.Common, name) {
+			return country, true
+		}
 	}
-	// fmt.Println(string(body))
+	for _, country := range countries {
+		for _, n := range country.Names() {
+			if strings.EqualFold(n, name) {
+				return country, true
+			}
+		}
+	}
+	return models.Country{}, false
+}
 
-	var countries []models.Country
-	// Unmarshal JSON into struct
-	if err := json.Unmarshal(body, &countries); err != nil {
-		fmt.Println("Error:", err)
+// SearchByCode looks a country up by its ISO 3166-1 alpha-2 (cca2), alpha-3
+// (cca3) or numeric (ccn3) code, or by its IOC code (cioc).
+func (s *Service) SearchByCode(code string, opts ...Option) (*models.CountryMetadata, error) {
+	if err := ValidateCode("code", code); err != nil {
+		return nil, err
+	}
+	l := newLookup(opts)
+	if err := ValidateFields("fields", l.fields); err != nil {
+		return nil, err
 	}
 
-	for _, country := range countries {
-		if country.Name.Common == name {
+	if cachedResults, found := s.cached(codeKey(code), l); found {
+		return cachedResults, nil
+	}
+	if ix := s.local(); ix != nil {
+		if meta, ok := ix.ByCode(code); ok {
+			l.report(s.indexFreshness())
+			return &meta, nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, code)
+	}
+
+	countries, err := fetchCountries(alphaURL + url.PathEscape(code) + l.query())
+	if err != nil {
+		return nil, err
+	}
 
-			countryMetaData := models.CountryMetadata{
-				Name:       country.Name.Common,
-				Population: country.Population,
+	for _, country := range countries {
+		for _, c := range country.Codes() {
+			if strings.EqualFold(c, code) {
+				countryMetaData := toMetadata(country)
+				s.store(country, &countryMetaData, l)
+				l.report(s.fetchedFreshness(l.key(codeKey(code))))
+				return &countryMetaData, nil
 			}
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, code)
+}
+
+// SearchByCapital returns the country whose capital matches the given city,
+// ignoring case. Countries with several capitals match on any of them.
+func (s *Service) SearchByCapital(capital string, opts ...Option) (*models.CountryMetadata, error) {
+	if err := ValidateName("capital", capital); err != nil {
+		return nil, err
+	}
+	l := newLookup(opts)
+	if err := ValidateFields("fields", l.fields); err != nil {
+		return nil, err
+	}
 
-			if len(country.Capital) > 0 {
-				countryMetaData.Capital = country.Capital[0]
+	if cachedResults, found := s.cached(capitalKey(capital), l); found {
+		return cachedResults, nil
+	}
+	if ix := s.local(); ix != nil {
+		if meta, ok := ix.ByCapital(capital); ok {
+			l.report(s.indexFreshness())
+			return &meta, nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, capital)
+	}
+
+	countries, err := fetchCountries(capitalURL + url.PathEscape(capital) + l.query())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, country := range countries {
+		for _, c := range country.Capital {
+			if strings.EqualFold(c, capital) {
+				countryMetaData := toMetadata(country)
+				s.store(country, &countryMetaData, l)
+				l.report(s.fetchedFreshness(l.key(capitalKey(capital))))
+				return &countryMetaData, nil
 			}
-			for _, curr := range country.Currencies {
-				countryMetaData.Currency = curr.Symbol
-				break
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, capital)
+}
+
+// SearchByCurrency returns every country using the given currency, which may
+// be an ISO 4217 code such as "EUR" or a symbol such as "€". Results are
+// sorted by country name, and each one lists the currencies that matched.
+func (s *Service) SearchByCurrency(currency string, opts ...Option) ([]models.CurrencyMatch, error) {
+	if err := ValidateCurrency("currency", currency); err != nil {
+		return nil, err
+	}
+	l := newLookup(opts)
+	if err := ValidateFields("fields", l.fields); err != nil {
+		return nil, err
+	}
+
+	// Upstream only searches by code, so symbols, and codes it does not
+	// know, are matched against the local index instead. Until it has
+	// loaded, they are not found: the load starts in the background rather
+	// than holding up the request for every chunk of the country list.
+	ix := s.local()
+	if ix != nil {
+		return matchCurrency(ix.All(), currency)
+	}
+	if IsCurrencyCode(currency) {
+		countries, err := fetchCountries(currencyURL + url.PathEscape(currency) + l.query("currencies"))
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		if err == nil {
+			var metas []models.CountryMetadata
+			for _, country := range countries {
+				countryMetaData := toMetadata(country)
+				s.store(country, &countryMetaData, l)
+				metas = append(metas, countryMetaData)
 			}
-			// Store results in cache before returning
-			s.cache.Set(name, &countryMetaData)
-			//fmt.Println(countryMetaData)
-			return &countryMetaData, nil
+			return matchCurrency(metas, currency)
+		}
+	}
+	s.loadIndexAsync()
+	return matchCurrency(nil, currency)
+}
+
+// matchCurrency returns the countries using currency, sorted by name, each
+// with the currencies that matched its code or symbol.
+func matchCurrency(countries []models.CountryMetadata, currency string) ([]models.CurrencyMatch, error) {
+	var matches []models.CurrencyMatch
+	for _, country := range countries {
+		matched := matchingCurrencies(country, currency)
+		if len(matched) == 0 {
+			continue
 		}
+		matches = append(matches, models.CurrencyMatch{
+			CountryMetadata:   country,
+			MatchedCurrencies: matched,
+		})
 	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: no country uses %s", ErrNotFound, currency)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Name < matches[j].Name
+	})
+	return matches, nil
+}
 
-	// Store results in cache before returning
+// matchingCurrencies returns the currencies of the country with the given
+// code or symbol.
+func matchingCurrencies(country models.CountryMetadata, currency string) []models.Currency {
+	var matched []models.Currency
+	for _, curr := range country.Currencies {
+		if strings.EqualFold(curr.Code, currency) || curr.Symbol == currency {
+			matched = append(matched, curr)
+		}
+	}
+	return matched
+}
 
-	return &models.CountryMetadata{}, nil
+// cached returns the cached country for key. A projected lookup can also be
+// served by the complete country cached by an unprojected one.
+func (s *Service) cached(key string, l lookup) (*models.CountryMetadata, bool) {
+	if entry, found := s.cache.GetEntry(l.key(key)); found {
+		l.report(entryFreshness(entry))
+		return &entry.Value, true
+	}
+	if l.projection() != "" {
+		if entry, found := s.cache.GetEntry(key); found {
+			l.report(entryFreshness(entry))
+			return &entry.Value, true
+		}
+	}
+	return nil, false
+}
+
+// store caches a country under its common name, all of its codes and all of
+// its capitals, so a lookup by any of them warms the others. Projected
+// countries are only cached for lookups with the same projection.
+func (s *Service) store(country models.Country, meta *models.CountryMetadata, l lookup) {
+	s.cache.Set(l.key(country.Name.Common), meta)
+	for _, code := range country.Codes() {
+		s.cache.Set(l.key(codeKey(code)), meta)
+	}
+	for _, capital := range country.Capital {
+		s.cache.Set(l.key(capitalKey(capital)), meta)
+	}
+}
+
+// codeKey namespaces country codes in the cache so they never collide with
+// country names.
+func codeKey(code string) string {
+	return "code:" + strings.ToUpper(code)
+}
+
+// capitalKey namespaces capitals in the cache. Capitals are matched without
+// regard to case, so the key is lowercased.
+func capitalKey(capital string) string {
+	return "capital:" + strings.ToLower(capital)
+}
+
+// currencies returns the currencies of a country sorted by code.
+func currencies(country models.Country) []models.Currency {
+	result := make([]models.Currency, 0, len(country.Currencies))
+	for code, curr := range country.Currencies {
+		result = append(result, models.Currency{
+			Code:   code,
+			Name:   curr.Name,
+			Symbol: curr.Symbol,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Code < result[j].Code
+	})
+	return result
+}
+
+// translations returns the common names of a country keyed by language. The
+// native names fill in languages upstream has no translation for.
+func translations(country models.Country) map[string]string {
+	if len(country.Translations) == 0 && len(country.Name.NativeName) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(country.Translations)+len(country.Name.NativeName))
+	for lang, t := range country.Translations {
+		result[lang] = t.Common
+	}
+	for lang, n := range country.Name.NativeName {
+		if _, ok := result[lang]; !ok {
+			result[lang] = n.Common
+		}
+	}
+	return result
+}
+
+// languages returns the languages of a country sorted by code.
+func languages(country models.Country) []models.Language {
+	if len(country.Languages) == 0 {
+		return nil
+	}
+	result := make([]models.Language, 0, len(country.Languages))
+	for code, name := range country.Languages {
+		result = append(result, models.Language{Code: code, Name: name})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Code < result[j].Code
+	})
+	return result
+}
+
+func toMetadata(country models.Country) models.CountryMetadata {
+	countryMetaData := models.CountryMetadata{
+		Name:         country.Name.Common,
+		Population:   country.Population,
+		CCA2:         country.CCA2,
+		CCA3:         country.CCA3,
+		CCN3:         country.CCN3,
+		CIOC:         country.CIOC,
+		Capitals:     country.Capital,
+		Currencies:   currencies(country),
+		Region:       country.Region,
+		Subregion:    country.Subregion,
+		Languages:    languages(country),
+		Borders:      country.Borders,
+		LatLng:       country.LatLng,
+		Area:         country.Area,
+		Landlocked:   country.Landlocked,
+		Timezones:    country.Timezones,
+		CallingCodes: country.IDD.CallingCodes(),
+		TLD:          country.TLD,
+		Translations: translations(country),
+	}
+	if country.Flags != (models.Flags{}) {
+		flags := country.Flags
+		countryMetaData.Flags = &flags
+	}
+
+	if len(countryMetaData.Capitals) > 0 {
+		countryMetaData.Capital = countryMetaData.Capitals[0]
+	}
+	if len(countryMetaData.Currencies) > 0 {
+		countryMetaData.Currency = countryMetaData.Currencies[0].Symbol
+	}
+	return countryMetaData
+}
+
+// fetchCountries calls the upstream API and decodes the list of countries it
+// returns. Failures are reported as one of the service errors.
+func fetchCountries(url string) ([]models.Country, error) {
+	var countries []models.Country
+	if err := fetchJSON(url, &countries); err != nil {
+		return nil, err
+	}
+	return countries, nil
+}
+
+// fetchJSON calls the upstream API and decodes its JSON response into v.
+func fetchJSON(url string, v any) error {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return upstreamError(err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return fmt.Errorf("%w: API returned status code: %d", ErrUpstreamUnavailable, resp.StatusCode)
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("%w: API returned status code: %d", ErrUpstreamBadResponse, resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return upstreamError(err)
+	}
+
+	// Unmarshal JSON into struct
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("%w: failed to decode response body: %v", ErrUpstreamBadResponse, err)
+	}
+	return nil
 }
@@ -2,10 +2,15 @@ package service
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/Prasang-money/searchSvc/cache"
 	"github.com/Prasang-money/searchSvc/models"
@@ -108,3 +113,1305 @@ func TestSearchCountries_APIError(t *testing.T) {
 		t.Fatalf("unexpected error message: %v", err)
 	}
 }
+
+func TestSearchCountries_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    error
+	}{
+		{
+			name: "upstream not found",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			want: ErrNotFound,
+		},
+		{
+			name: "no exact match",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewEncoder(w).Encode([]models.Country{{Name: models.Name{Common: "Otherland"}}})
+			},
+			want: ErrNotFound,
+		},
+		{
+			name: "unavailable",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			want: ErrUpstreamUnavailable,
+		},
+		{
+			name: "unexpected status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			},
+			want: ErrUpstreamBadResponse,
+		},
+		{
+			name: "malformed body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("not json"))
+			},
+			want: ErrUpstreamBadResponse,
+		},
+		{
+			name: "timeout",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+			},
+			want: ErrTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(tt.handler)
+			defer ts.Close()
+
+			origURL, origTranslation, origAll, origClient := baseURL, translationURL, allURL, httpClient
+			baseURL, translationURL, allURL = ts.URL+"/", ts.URL+"/", ts.URL+"/all"
+			httpClient = &http.Client{Timeout: 50 * time.Millisecond}
+			defer func() { baseURL, translationURL, allURL, httpClient = origURL, origTranslation, origAll, origClient }()
+
+			svc := NewService(cache.NewCache(10))
+			_, err := svc.SearchCountries("Testland")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestSearchCountries_EmptyName(t *testing.T) {
+	svc := NewService(cache.NewCache(10))
+
+	_, err := svc.SearchCountries("")
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"simple", "India", false},
+		{"spaces", "United States", false},
+		{"accents and apostrophe", "Côte d'Ivoire", false},
+		{"hyphen", "Guinea-Bissau", false},
+		{"parentheses", "Korea (Republic of)", false},
+		{"empty", "", true},
+		{"blank", "   ", true},
+		{"too long", strings.Repeat("a", MaxNameLength+1), true},
+		{"slash", "India/all", true},
+		{"question mark", "India?fullText=true", true},
+		{"percent", "India%2F", true},
+		{"digits", "356", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateName("name", tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateName(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidInput) {
+				t.Fatalf("expected ErrInvalidInput, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSearchCountries_EscapesName(t *testing.T) {
+	var gotPath string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		_ = json.NewEncoder(w).Encode([]models.Country{{Name: models.Name{Common: "Côte d'Ivoire"}}})
+	}))
+	defer ts.Close()
+
+	orig := baseURL
+	baseURL = ts.URL + "/"
+	defer func() { baseURL = orig }()
+
+	svc := NewService(cache.NewCache(10))
+	if _, err := svc.SearchCountries("Côte d'Ivoire"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "/C%C3%B4te%20d%27Ivoire"; gotPath != want {
+		t.Fatalf("expected upstream path %s, got %s", want, gotPath)
+	}
+}
+
+func TestSearchByCode_CrossKeys(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/in" {
+			t.Errorf("unexpected upstream path %s", r.URL.Path)
+		}
+		country := models.Country{
+			Name:       models.Name{Common: "India"},
+			CCA2:       "IN",
+			CCA3:       "IND",
+			CCN3:       "356",
+			CIOC:       "IND",
+			Population: 1380004385,
+			Capital:    []string{"New Delhi"},
+		}
+		_ = json.NewEncoder(w).Encode([]models.Country{country})
+	}))
+	defer ts.Close()
+
+	orig := alphaURL
+	alphaURL = ts.URL + "/"
+	defer func() { alphaURL = orig }()
+
+	svc := NewService(cache.NewCache(10))
+
+	res, err := svc.SearchByCode("in")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Name != "India" {
+		t.Fatalf("expected India, got %s", res.Name)
+	}
+
+	// Every other key for the same country is now served from cache
+	for _, code := range []string{"IN", "ind", "356"} {
+		res, err := svc.SearchByCode(code)
+		if err != nil || res.Name != "India" {
+			t.Fatalf("SearchByCode(%q) = %v, %v", code, res, err)
+		}
+	}
+	res, err = svc.SearchCountries("India")
+	if err != nil || res.Name != "India" {
+		t.Fatalf("SearchCountries(India) = %v, %v", res, err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 upstream call, got %d", calls)
+	}
+}
+
+func TestSearchByCode_Invalid(t *testing.T) {
+	svc := NewService(cache.NewCache(10))
+
+	for _, code := range []string{"", "I", "INDI", "1N", "12", "%2F"} {
+		if _, err := svc.SearchByCode(code); !errors.Is(err, ErrInvalidInput) {
+			t.Fatalf("SearchByCode(%q): expected ErrInvalidInput, got %v", code, err)
+		}
+	}
+}
+
+func TestSearchByCapital_MultipleCapitals(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		country := models.Country{
+			Name:       models.Name{Common: "South Africa"},
+			CCA2:       "ZA",
+			Population: 59308690,
+			Capital:    []string{"Pretoria", "Bloemfontein", "Cape Town"},
+		}
+		_ = json.NewEncoder(w).Encode([]models.Country{country})
+	}))
+	defer ts.Close()
+
+	orig := capitalURL
+	capitalURL = ts.URL + "/"
+	defer func() { capitalURL = orig }()
+
+	svc := NewService(cache.NewCache(10))
+
+	res, err := svc.SearchByCapital("cape town")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Name != "South Africa" {
+		t.Fatalf("expected South Africa, got %s", res.Name)
+	}
+
+	// The other capitals, the name and the codes are all cached now
+	for _, capital := range []string{"PRETORIA", "Bloemfontein"} {
+		if res, err := svc.SearchByCapital(capital); err != nil || res.Name != "South Africa" {
+			t.Fatalf("SearchByCapital(%q) = %v, %v", capital, res, err)
+		}
+	}
+	if res, err := svc.SearchByCode("za"); err != nil || res.Name != "South Africa" {
+		t.Fatalf("SearchByCode(za) = %v, %v", res, err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 upstream call, got %d", calls)
+	}
+}
+
+func TestSearchByCapital_PartialMatchIsNotFound(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// upstream matches capitals by substring
+		country := models.Country{
+			Name:    models.Name{Common: "Papua New Guinea"},
+			Capital: []string{"Port Moresby"},
+		}
+		_ = json.NewEncoder(w).Encode([]models.Country{country})
+	}))
+	defer ts.Close()
+
+	orig := capitalURL
+	capitalURL = ts.URL + "/"
+	defer func() { capitalURL = orig }()
+
+	svc := NewService(cache.NewCache(10))
+	if _, err := svc.SearchByCapital("Port"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func currencyTestServer(t *testing.T) *httptest.Server {
+	countries := []models.Country{
+		{
+			Name:       models.Name{Common: "Zimbabwe"},
+			CCA2:       "ZW",
+			CCA3:       "ZWE",
+			Currencies: map[string]models.Currencies{"ZWL": {Name: "Zimbabwean dollar", Symbol: "$"}, "USD": {Name: "United States dollar", Symbol: "$"}},
+		},
+		{
+			Name:       models.Name{Common: "United States"},
+			CCA2:       "US",
+			CCA3:       "USA",
+			Currencies: map[string]models.Currencies{"USD": {Name: "United States dollar", Symbol: "$"}},
+		},
+		{
+			Name:       models.Name{Common: "Germany"},
+			CCA2:       "DE",
+			CCA3:       "DEU",
+			Currencies: map[string]models.Currencies{"EUR": {Name: "Euro", Symbol: "€"}},
+		},
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/all":
+			if r.URL.Query().Get("fields") == "" {
+				t.Errorf("expected fields parameter on /all")
+			}
+			_ = json.NewEncoder(w).Encode(countries)
+		case strings.EqualFold(r.URL.Path, "/currency/usd"):
+			_ = json.NewEncoder(w).Encode(countries[:2])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestSearchByCurrency(t *testing.T) {
+	ts := currencyTestServer(t)
+	defer ts.Close()
+
+	origCurrency, origAll := currencyURL, allURL
+	currencyURL, allURL = ts.URL+"/currency/", ts.URL+"/all"
+	defer func() { currencyURL, allURL = origCurrency, origAll }()
+
+	c := cache.NewCache(10)
+	svc := NewService(c)
+
+	tests := []struct {
+		name      string
+		currency  string
+		want      []string
+		wantMatch int
+	}{
+		{"by code", "usd", []string{"United States", "Zimbabwe"}, 1},
+		{"by symbol", "$", []string{"United States", "Zimbabwe"}, 0},
+		{"symbol unknown upstream", "€", []string{"Germany"}, 1},
+	}
+
+	// Before the local index has loaded, codes are looked up upstream, and
+	// the matching countries cached for name lookups
+	if res, err := svc.SearchByCurrency("usd"); err != nil || len(res) != 2 {
+		t.Fatalf("expected two countries using USD, got %v, %v", res, err)
+	}
+	if _, found := c.Get("Zimbabwe"); !found {
+		t.Fatalf("expected Zimbabwe to be cached")
+	}
+
+	// Symbols are matched against the local index, which a request does
+	// not wait for: it starts loading it and finds nothing
+	if _, err := svc.SearchByCurrency("$"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound before the index loaded, got %v", err)
+	}
+	for deadline := time.Now().Add(time.Second); svc.local() == nil; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the index to load in the background")
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := svc.SearchByCurrency(tt.currency)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var names []string
+			for _, m := range res {
+				names = append(names, m.Name)
+				for _, curr := range m.MatchedCurrencies {
+					if curr.Name == "" {
+						t.Fatalf("expected currency name for %s", m.Name)
+					}
+				}
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("expected %v, got %v", tt.want, names)
+			}
+			if tt.wantMatch > 0 && len(res[0].MatchedCurrencies) != tt.wantMatch {
+				t.Fatalf("expected %d matched currencies, got %v", tt.wantMatch, res[0].MatchedCurrencies)
+			}
+		})
+	}
+
+	// Zimbabwe uses two dollar currencies, both match the symbol, in code order
+	res, _ := svc.SearchByCurrency("$")
+	zw := res[1].MatchedCurrencies
+	if len(zw) != 2 || zw[0].Code != "USD" || zw[1].Code != "ZWL" {
+		t.Fatalf("unexpected Zimbabwe currencies: %v", zw)
+	}
+
+	if _, err := svc.SearchByCurrency("XYZ"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestToMetadata_StableOrder(t *testing.T) {
+	country := models.Country{
+		Name:    models.Name{Common: "Multiland"},
+		Capital: []string{"First City", "Second City"},
+		Currencies: map[string]models.Currencies{
+			"ZZZ": {Name: "Zed", Symbol: "Z"},
+			"AAA": {Name: "Ay", Symbol: "A"},
+			"MMM": {Name: "Em", Symbol: "M"},
+		},
+	}
+
+	// map iteration order is random, so check repeatedly
+	for i := 0; i < 20; i++ {
+		meta := toMetadata(country)
+		if meta.Capital != "First City" || len(meta.Capitals) != 2 {
+			t.Fatalf("unexpected capitals: %q %v", meta.Capital, meta.Capitals)
+		}
+		if meta.Currency != "A" {
+			t.Fatalf("expected currency of lowest code, got %q", meta.Currency)
+		}
+		var codes []string
+		for _, curr := range meta.Currencies {
+			codes = append(codes, curr.Code)
+		}
+		if strings.Join(codes, ",") != "AAA,MMM,ZZZ" {
+			t.Fatalf("expected currencies sorted by code, got %v", codes)
+		}
+	}
+}
+
+func TestToMetadata_ExtendedFields(t *testing.T) {
+	country := models.Country{
+		Name:      models.Name{Common: "Switzerland"},
+		Region:    "Europe",
+		Subregion: "Western Europe",
+		Languages: map[string]string{"roh": "Romansh", "fra": "French", "gsw": "Swiss German", "ita": "Italian"},
+		Borders:   []string{"AUT", "FRA", "ITA", "LIE", "DEU"},
+		LatLng:    []float64{47, 8},
+		Area:      41284,
+		Timezones: []string{"UTC+01:00"},
+		Flags:     models.Flags{PNG: "https://flagcdn.com/w320/ch.png", SVG: "https://flagcdn.com/ch.svg"},
+		IDD:       models.IDD{Root: "+4", Suffixes: []string{"1"}},
+		TLD:       []string{".ch"},
+	}
+
+	meta := toMetadata(country)
+	if meta.Region != "Europe" || meta.Subregion != "Western Europe" {
+		t.Fatalf("unexpected region: %q %q", meta.Region, meta.Subregion)
+	}
+	var codes []string
+	for _, l := range meta.Languages {
+		codes = append(codes, l.Code)
+	}
+	if strings.Join(codes, ",") != "fra,gsw,ita,roh" {
+		t.Fatalf("expected languages sorted by code, got %v", codes)
+	}
+	if len(meta.Borders) != 5 || len(meta.LatLng) != 2 || meta.Area != 41284 {
+		t.Fatalf("unexpected geography: %v %v %v", meta.Borders, meta.LatLng, meta.Area)
+	}
+	if meta.Flags == nil || meta.Flags.SVG != "https://flagcdn.com/ch.svg" {
+		t.Fatalf("unexpected flags: %v", meta.Flags)
+	}
+	if len(meta.CallingCodes) != 1 || meta.CallingCodes[0] != "+41" {
+		t.Fatalf("unexpected calling codes: %v", meta.CallingCodes)
+	}
+
+	// Countries sharing a root over many area codes report the root only
+	us := models.IDD{Root: "+1", Suffixes: []string{"201", "202", "203"}}
+	if codes := us.CallingCodes(); len(codes) != 1 || codes[0] != "+1" {
+		t.Fatalf("unexpected calling codes: %v", codes)
+	}
+}
+
+func TestSearchCountries_Fields(t *testing.T) {
+	var queries []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("fields"))
+		country := models.Country{
+			Name:       models.Name{Common: "Testland"},
+			CCA2:       "TL",
+			Population: 12345,
+			Capital:    []string{"T-City"},
+		}
+		if r.URL.Query().Get("fields") == "" {
+			country.Region = "Nowhere"
+		}
+		_ = json.NewEncoder(w).Encode([]models.Country{country})
+	}))
+	defer ts.Close()
+
+	origBase, origAlpha := baseURL, alphaURL
+	baseURL, alphaURL = ts.URL+"/", ts.URL+"/"
+	defer func() { baseURL, alphaURL = origBase, origAlpha }()
+
+	svc := NewService(cache.NewCache(10))
+
+	// Unknown fields are rejected before going upstream
+	if _, err := svc.SearchCountries("Testland", WithFields("motto")); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+
+	// Projected lookups send the mapped fields upstream
+	if _, err := svc.SearchCountries("Testland", WithFields("population", "callingCodes")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "capital,cca2,cca3,ccn3,cioc,idd,name,population"; queries[0] != want {
+		t.Fatalf("expected upstream fields %q, got %q", want, queries[0])
+	}
+
+	// The same projection, in any order and even via a cross key, is cached
+	if _, err := svc.SearchCountries("Testland", WithFields("callingCodes", "population")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.SearchByCode("TL", WithFields("population", "callingCodes")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(queries) != 1 {
+		t.Fatalf("expected 1 upstream call, got %d", len(queries))
+	}
+
+	// A partial country must not be served to an unprojected lookup
+	res, err := svc.SearchCountries("Testland")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Region != "Nowhere" || len(queries) != 2 {
+		t.Fatalf("expected a complete upstream fetch, got %+v after %d calls", res, len(queries))
+	}
+
+	// ...but a complete country serves any projection
+	if _, err := svc.SearchCountries("Testland", WithFields("region")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(queries) != 2 {
+		t.Fatalf("expected projected lookup to be served from cache, got %d calls", len(queries))
+	}
+}
+
+func TestSearchCountries_FieldsWithLanguage(t *testing.T) {
+	var queries []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("fields"))
+		_ = json.NewEncoder(w).Encode([]models.Country{{Name: models.Name{Common: "Testland"}, CCA2: "TL"}})
+	}))
+	defer ts.Close()
+
+	origAlpha := alphaURL
+	alphaURL = ts.URL + "/"
+	defer func() { alphaURL = origAlpha }()
+
+	svc := NewService(cache.NewCache(10))
+
+	// English names need no translations
+	if _, err := svc.SearchByCode("TL", WithFields("name", "flags"), WithLanguage(models.DefaultLanguage)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Other languages fetch them, and are not served the untranslated country
+	if _, err := svc.SearchByCode("TL", WithLanguage("fr"), WithFields("name", "flags")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"capital,cca2,cca3,ccn3,cioc,flags,name", "capital,cca2,cca3,ccn3,cioc,flags,name,translations"}
+	if strings.Join(queries, " ") != strings.Join(want, " ") {
+		t.Fatalf("expected upstream fields %q, got %q", want, queries)
+	}
+}
+
+func TestSearchCountries_LocalizedNames(t *testing.T) {
+	germany := models.Country{
+		Name: models.Name{
+			Common:     "Germany",
+			Official:   "Federal Republic of Germany",
+			NativeName: map[string]models.Translation{"deu": {Common: "Deutschland", Official: "Bundesrepublik Deutschland"}},
+		},
+		CCA2:         "DE",
+		Translations: map[string]models.Translation{"fra": {Common: "Allemagne", Official: "République fédérale d'Allemagne"}},
+	}
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch strings.ToLower(r.URL.Path) {
+		case "/name/deutschland", "/translation/allemagne":
+			_ = json.NewEncoder(w).Encode([]models.Country{germany})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	origBase, origTranslation := baseURL, translationURL
+	baseURL, translationURL = ts.URL+"/name/", ts.URL+"/translation/"
+	defer func() { baseURL, translationURL = origBase, origTranslation }()
+
+	svc := NewService(cache.NewCache(10))
+
+	for _, name := range []string{"Deutschland", "allemagne"} {
+		paths = nil
+		res, err := svc.SearchCountries(name)
+		if err != nil {
+			t.Fatalf("SearchCountries(%q): unexpected error: %v", name, err)
+		}
+		if res.Name != "Germany" {
+			t.Fatalf("SearchCountries(%q): expected Germany, got %s", name, res.Name)
+		}
+		if res.LocalizedName("fr") != "Allemagne" || res.LocalizedName("de") != "Deutschland" {
+			t.Fatalf("unexpected translations: %v", res.Translations)
+		}
+		if res.LocalizedName("ja") != "Germany" {
+			t.Fatalf("expected English fallback, got %s", res.LocalizedName("ja"))
+		}
+	}
+	if len(paths) != 2 || paths[1] != "/translation/allemagne" {
+		t.Fatalf("expected fallback to the translation endpoint, got %v", paths)
+	}
+}
+
+func fuzzyTestServer(t *testing.T, calls *int) *httptest.Server {
+	countries := []models.Country{
+		{Name: models.Name{Common: "Philippines", Official: "Republic of the Philippines"}, CCA3: "PHL", AltSpellings: []string{"PH", "Pilipinas"}, Population: 109581085},
+		{Name: models.Name{Common: "Colombia", Official: "Republic of Colombia"}, CCA3: "COL", AltSpellings: []string{"CO"}, Population: 50882884},
+		{Name: models.Name{Common: "Columbia Island"}, CCA3: "XCI", Population: 10},
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/all" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// the list is fetched in field chunks, count one load per first chunk
+		fields := r.URL.Query().Get("fields")
+		if !strings.HasPrefix(fields, "cca3,") {
+			t.Errorf("unexpected index fields %q", fields)
+		}
+		if fields == allFieldChunks[0] {
+			*calls++
+		}
+		_ = json.NewEncoder(w).Encode(countries)
+	}))
+}
+
+func TestFuzzySearch(t *testing.T) {
+	var calls int
+	ts := fuzzyTestServer(t, &calls)
+	defer ts.Close()
+
+	origAll := allURL
+	allURL = ts.URL + "/all"
+	defer func() { allURL = origAll }()
+
+	svc := NewService(cache.NewCache(10))
+
+	res, err := svc.FuzzySearch("Phillipines", DefaultFuzzyThreshold, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res) != 1 || res[0].Name != "Philippines" || res[0].Score < DefaultFuzzyThreshold {
+		t.Fatalf("unexpected matches: %+v", res)
+	}
+
+	res, err = svc.FuzzySearch("pilipinas", DefaultFuzzyThreshold, 5)
+	if err != nil || len(res) != 1 || res[0].MatchedName != "Pilipinas" {
+		t.Fatalf("expected a match on the alternative spelling, got %+v, %v", res, err)
+	}
+
+	if res, _ := svc.FuzzySearch("Atlantis", DefaultFuzzyThreshold, 5); len(res) != 0 {
+		t.Fatalf("expected no matches, got %+v", res)
+	}
+	if _, err := svc.FuzzySearch("Atlantis", 2, 5); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+
+	// The index is loaded once
+	if calls != 1 {
+		t.Fatalf("expected 1 index load, got %d", calls)
+	}
+}
+
+func TestSearchCountries_Suggestions(t *testing.T) {
+	var calls int
+	ts := fuzzyTestServer(t, &calls)
+	defer ts.Close()
+
+	origBase, origTranslation, origAll := baseURL, translationURL, allURL
+	baseURL, translationURL, allURL = ts.URL+"/name/", ts.URL+"/translation/", ts.URL+"/all"
+	defer func() { baseURL, translationURL, allURL = origBase, origTranslation, origAll }()
+
+	svc := NewService(cache.NewCache(10))
+
+	// A miss does not load the index just to suggest
+	_, err := svc.SearchCountries("Columbia")
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected NotFoundError, got %v", err)
+	}
+	if len(notFound.Suggestions) != 0 || calls != 0 {
+		t.Fatalf("expected no suggestions and no index load, got %v after %d loads", notFound.Suggestions, calls)
+	}
+
+	// Once loaded, the index suggests close matches
+	if err := svc.RefreshIndex(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = svc.SearchCountries("Columbia")
+	if !errors.As(err, &notFound) || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected NotFoundError, got %v", err)
+	}
+	if strings.Join(notFound.Suggestions, ",") != "Colombia" {
+		t.Fatalf("unexpected suggestions: %v", notFound.Suggestions)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	var calls int
+	ts := fuzzyTestServer(t, &calls)
+	defer ts.Close()
+
+	origAll := allURL
+	allURL = ts.URL + "/all"
+	defer func() { allURL = origAll }()
+
+	svc := NewService(cache.NewCache(10))
+
+	for _, prefix := range []string{"col", "co", "c"} {
+		res, err := svc.Suggest(prefix, 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(res) != 2 || res[0].Name != "Colombia" || res[1].Name != "Columbia Island" {
+			t.Fatalf("Suggest(%q): expected Colombia then Columbia Island, got %+v", prefix, res)
+		}
+	}
+	if res, _ := svc.Suggest("col", 1); len(res) != 1 {
+		t.Fatalf("expected limit to apply, got %+v", res)
+	}
+	if calls != 1 {
+		t.Fatalf("expected suggestions to be served locally after one load, got %d calls", calls)
+	}
+}
+
+// indexTestServer serves *countries on /all, projected to the requested
+// fields like upstream does, and fails with 500 while *fail is set. Any
+// other path is counted as a remote lookup.
+func indexTestServer(t *testing.T, countries *[]models.Country, fail *bool, remote *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/all" {
+			*remote++
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if *fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fields := strings.Split(r.URL.Query().Get("fields"), ",")
+		if len(fields) > 10 {
+			t.Errorf("upstream accepts at most 10 fields, got %v", fields)
+		}
+
+		var projected []map[string]json.RawMessage
+		for _, country := range *countries {
+			data, _ := json.Marshal(country)
+			var all map[string]json.RawMessage
+			_ = json.Unmarshal(data, &all)
+			item := make(map[string]json.RawMessage)
+			for _, f := range fields {
+				if v, ok := all[f]; ok {
+					item[f] = v
+				}
+			}
+			projected = append(projected, item)
+		}
+		_ = json.NewEncoder(w).Encode(projected)
+	}))
+}
+
+func indexTestCountries() []models.Country {
+	return []models.Country{
+		{
+			Name:         models.Name{Common: "Germany", Official: "Federal Republic of Germany"},
+			CCA2:         "DE",
+			CCA3:         "DEU",
+			Population:   83240525,
+			Capital:      []string{"Berlin"},
+			Currencies:   map[string]models.Currencies{"EUR": {Name: "Euro", Symbol: "€"}},
+			Region:       "Europe",
+			Languages:    map[string]string{"deu": "German"},
+			Translations: map[string]models.Translation{"fra": {Common: "Allemagne"}, "deu": {Common: "Deutschland"}},
+		},
+		{
+			Name:         models.Name{Common: "Switzerland", Official: "Swiss Confederation"},
+			CCA2:         "CH",
+			CCA3:         "CHE",
+			Population:   8654622,
+			Capital:      []string{"Bern"},
+			Currencies:   map[string]models.Currencies{"CHF": {Name: "Swiss franc", Symbol: "Fr."}},
+			Region:       "Europe",
+			Languages:    map[string]string{"deu": "German", "fra": "French", "ita": "Italian", "roh": "Romansh"},
+			Translations: map[string]models.Translation{"deu": {Common: "Schweiz"}},
+		},
+	}
+}
+
+func TestLocalIndex(t *testing.T) {
+	countries := indexTestCountries()
+	var fail bool
+	var remote int
+	ts := indexTestServer(t, &countries, &fail, &remote)
+	defer ts.Close()
+
+	origBase, origTranslation, origAlpha, origCapital, origAll := baseURL, translationURL, alphaURL, capitalURL, allURL
+	baseURL, translationURL, alphaURL, capitalURL, allURL = ts.URL+"/name/", ts.URL+"/translation/", ts.URL+"/alpha/", ts.URL+"/capital/", ts.URL+"/all"
+	defer func() {
+		baseURL, translationURL, alphaURL, capitalURL, allURL = origBase, origTranslation, origAlpha, origCapital, origAll
+	}()
+
+	svc := NewService(cache.NewCache(10))
+	if err := svc.RefreshIndex(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Every field chunk is merged into the indexed country
+	de, err := svc.SearchCountries("Deutschland")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if de.Name != "Germany" || de.Capital != "Berlin" || len(de.Languages) != 1 || de.Translations["fra"] != "Allemagne" {
+		t.Fatalf("unexpected country: %+v", de)
+	}
+	if res, err := svc.SearchByCode("che"); err != nil || res.Name != "Switzerland" {
+		t.Fatalf("expected Switzerland by code, got %+v, %v", res, err)
+	}
+	if res, err := svc.SearchByCapital("BERN"); err != nil || res.Name != "Switzerland" {
+		t.Fatalf("expected Switzerland by capital, got %+v, %v", res, err)
+	}
+	if _, err := svc.SearchByCode("XX"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if remote != 0 {
+		t.Fatalf("expected lookups to be answered locally, got %d upstream calls", remote)
+	}
+
+	// A failed refresh keeps the current index
+	fail = true
+	if err := svc.RefreshIndex(); err == nil {
+		t.Fatalf("expected refresh to fail")
+	}
+	if page, err := svc.ListCountries(ListQuery{Query: "german"}); err != nil || page.Total != 2 {
+		t.Fatalf("expected the previous index to be kept, got %+v, %v", page, err)
+	}
+
+	// A successful refresh swaps the new index in
+	fail = false
+	countries = append(countries, models.Country{Name: models.Name{Common: "France"}, CCA3: "FRA", Capital: []string{"Paris"}})
+	if err := svc.RefreshIndex(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if page, err := svc.ListCountries(ListQuery{Query: "paris"}); err != nil || page.Total != 1 || page.Countries[0].Name != "France" {
+		t.Fatalf("expected France after refresh, got %+v, %v", page, err)
+	}
+}
+
+func TestListCountries_Search(t *testing.T) {
+	countries := indexTestCountries()
+	var fail bool
+	var remote int
+	ts := indexTestServer(t, &countries, &fail, &remote)
+	defer ts.Close()
+
+	origAll := allURL
+	allURL = ts.URL + "/all"
+	defer func() { allURL = origAll }()
+
+	svc := NewService(cache.NewCache(10))
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"german", []string{"Germany", "Switzerland"}},
+		{"swiss franc", []string{"Switzerland"}},
+		{"EUR", []string{"Germany"}},
+		{"federal republic", []string{"Germany"}},
+		{"atlantis", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			page, err := svc.ListCountries(ListQuery{Query: tt.query, Limit: 10})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var names []string
+			for _, hit := range page.Countries {
+				names = append(names, hit.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("expected %v, got %v", tt.want, names)
+			}
+		})
+	}
+}
+
+func TestListCountries(t *testing.T) {
+	countries := append(indexTestCountries(),
+		models.Country{Name: models.Name{Common: "Austria"}, CCA3: "AUT", Population: 8917205, Area: 83871, Region: "Europe", Landlocked: true, Languages: map[string]string{"bar": "Austro-Bavarian German"}, Currencies: map[string]models.Currencies{"EUR": {Name: "Euro", Symbol: "€"}}},
+		models.Country{Name: models.Name{Common: "Japan"}, CCA3: "JPN", Population: 125836021, Area: 377930, Region: "Asia", Languages: map[string]string{"jpn": "Japanese"}},
+	)
+	countries[0].Area = 357114
+	countries[1].Area, countries[1].Landlocked = 41284, true
+	var fail bool
+	var remote int
+	ts := indexTestServer(t, &countries, &fail, &remote)
+	defer ts.Close()
+
+	origAll := allURL
+	allURL = ts.URL + "/all"
+	defer func() { allURL = origAll }()
+
+	svc := NewService(cache.NewCache(10))
+
+	ten, million := 10000000, 1000000
+	landlocked, coastal := true, false
+	tests := []struct {
+		name  string
+		query ListQuery
+		want  []string
+	}{
+		{"everything by name", ListQuery{}, []string{"Austria", "Germany", "Japan", "Switzerland"}},
+		{"region", ListQuery{Filter: Filter{Region: "europe"}}, []string{"Austria", "Germany", "Switzerland"}},
+		{"min population", ListQuery{Filter: Filter{MinPopulation: &ten}}, []string{"Germany", "Japan"}},
+		{"population range", ListQuery{Filter: Filter{MinPopulation: &million, MaxPopulation: &ten}}, []string{"Austria", "Switzerland"}},
+		{"language code", ListQuery{Filter: Filter{Language: "DEU"}}, []string{"Germany", "Switzerland"}},
+		{"language name", ListQuery{Filter: Filter{Language: "japanese"}}, []string{"Japan"}},
+		{"currency code", ListQuery{Filter: Filter{Currency: "eur"}}, []string{"Austria", "Germany"}},
+		{"currency symbol", ListQuery{Filter: Filter{Currency: "Fr."}}, []string{"Switzerland"}},
+		{"landlocked", ListQuery{Filter: Filter{Landlocked: &landlocked}}, []string{"Austria", "Switzerland"}},
+		{"coastal", ListQuery{Filter: Filter{Landlocked: &coastal}}, []string{"Germany", "Japan"}},
+		{"population desc", ListQuery{Sort: "-population"}, []string{"Japan", "Germany", "Austria", "Switzerland"}},
+		{"area asc", ListQuery{Sort: "area"}, []string{"Switzerland", "Austria", "Germany", "Japan"}},
+		{"name desc", ListQuery{Sort: "-name"}, []string{"Switzerland", "Japan", "Germany", "Austria"}},
+		{"search by relevance", ListQuery{Query: "german"}, []string{"Germany", "Switzerland", "Austria"}},
+		{"search sorted and filtered", ListQuery{Query: "german", Sort: "area", Filter: Filter{Landlocked: &landlocked}}, []string{"Switzerland", "Austria"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := svc.ListCountries(tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var names []string
+			for _, hit := range page.Countries {
+				names = append(names, hit.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") || page.Total != len(tt.want) {
+				t.Fatalf("expected %v, got %v (total %d)", tt.want, names, page.Total)
+			}
+		})
+	}
+
+	// Cursors walk the pages in order
+	var names []string
+	query := ListQuery{Sort: "-population", Limit: 3}
+	for pages := 0; ; pages++ {
+		if pages > 2 {
+			t.Fatalf("expected 2 pages")
+		}
+		page, err := svc.ListCountries(query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if page.Total != 4 {
+			t.Fatalf("expected a total of 4, got %d", page.Total)
+		}
+		for _, hit := range page.Countries {
+			names = append(names, hit.Name)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+	if strings.Join(names, ",") != "Japan,Germany,Austria,Switzerland" {
+		t.Fatalf("unexpected pages: %v", names)
+	}
+
+	// A cursor points after the last country of its page, so removing a
+	// country of an earlier page does not skip any of the next
+	first, err := svc.ListCountries(ListQuery{Sort: "-population", Limit: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	all := countries
+	countries = slices.DeleteFunc(slices.Clone(all), func(c models.Country) bool { return c.Name.Common == "Japan" })
+	if err := svc.RefreshIndex(); err != nil {
+		t.Fatalf("refreshing index: %v", err)
+	}
+	next, err := svc.ListCountries(ListQuery{Sort: "-population", Limit: 2, Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	names = nil
+	for _, hit := range next.Countries {
+		names = append(names, hit.Name)
+	}
+	if strings.Join(names, ",") != "Austria,Switzerland" {
+		t.Fatalf("expected the page after Germany, got %v", names)
+	}
+	countries = all
+
+	// Cursors only continue the query they were issued for
+	for _, other := range []ListQuery{
+		{Sort: "population", Cursor: first.NextCursor},
+		{Sort: "-population", Query: "german", Cursor: first.NextCursor},
+		{Sort: "-population", Filter: Filter{Region: "Europe"}, Cursor: first.NextCursor},
+	} {
+		_, err := svc.ListCountries(other)
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Field != "cursor" {
+			t.Fatalf("expected a cursor ValidationError for %+v, got %v", other, err)
+		}
+	}
+	// but may change the page size
+	if _, err := svc.ListCountries(ListQuery{Sort: "-population", Limit: 1, Cursor: first.NextCursor}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	negative := -1
+	for _, bad := range []ListQuery{
+		{Sort: "capital"},
+		{Cursor: "not a cursor"},
+		{Cursor: "bzoy"},
+		{Filter: Filter{MinPopulation: &negative}},
+		{Filter: Filter{MinPopulation: &ten, MaxPopulation: &million}},
+		{Filter: Filter{Currency: "euros!"}},
+	} {
+		if _, err := svc.ListCountries(bad); !errors.Is(err, ErrInvalidInput) {
+			t.Fatalf("expected ErrInvalidInput for %+v, got %v", bad, err)
+		}
+	}
+}
+
+func TestBatchLookup(t *testing.T) {
+	countries := map[string]models.Country{
+		"/name/india": {Name: models.Name{Common: "India"}, CCA2: "IN", CCA3: "IND", Population: 1380004385},
+		"/alpha/de":   {Name: models.Name{Common: "Germany"}, CCA2: "DE", CCA3: "DEU", Population: 83240525},
+	}
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("Country %c", 'a'+i)
+		countries["/name/"+strings.ToLower(name)] = models.Country{Name: models.Name{Common: name}}
+	}
+
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	inFlight, maxInFlight := 0, 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.ToLower(r.URL.Path)
+		mu.Lock()
+		calls[path]++
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		time.Sleep(5 * time.Millisecond)
+		country, ok := countries[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]models.Country{country})
+	}))
+	defer ts.Close()
+
+	origBase, origTranslation, origAlpha, origAll := baseURL, translationURL, alphaURL, allURL
+	baseURL, translationURL, alphaURL, allURL = ts.URL+"/name/", ts.URL+"/translation/", ts.URL+"/alpha/", ts.URL+"/all"
+	defer func() { baseURL, translationURL, alphaURL, allURL = origBase, origTranslation, origAlpha, origAll }()
+
+	c := cache.NewCache(100)
+	svc := NewService(c)
+
+	items := []models.BatchItem{{Name: "India"}, {Code: "de"}, {Name: "Atlantis"}, {Code: "DE"}, {Name: "India", Code: "IN"}, {}}
+	for i := 0; i < 20; i++ {
+		items = append(items, models.BatchItem{Name: fmt.Sprintf("Country %c", 'a'+i)})
+	}
+	results, err := svc.BatchLookup(items, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != len(items) {
+		t.Fatalf("expected %d results, got %d", len(items), len(results))
+	}
+	if results[0].Country == nil || results[0].Country.Name != "India" {
+		t.Fatalf("unexpected result for India: %+v", results[0])
+	}
+	if results[1].Country == nil || results[1].Country.Name != "Germany" || results[3].Country != results[1].Country {
+		t.Fatalf("expected both DE items to share one lookup: %+v, %+v", results[1], results[3])
+	}
+	if !errors.Is(results[2].Err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for Atlantis, got %v", results[2].Err)
+	}
+	if !errors.Is(results[4].Err, ErrInvalidInput) || !errors.Is(results[5].Err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for malformed items, got %v, %v", results[4].Err, results[5].Err)
+	}
+	for _, r := range results[6:] {
+		if r.Err != nil || r.Country.Name != r.Item.Name {
+			t.Fatalf("unexpected result %+v", r)
+		}
+	}
+	if calls["/alpha/de"] != 1 {
+		t.Fatalf("expected repeated items to be looked up once, got %d calls", calls["/alpha/de"])
+	}
+	if maxInFlight > batchParallelism {
+		t.Fatalf("expected at most %d concurrent lookups, got %d", batchParallelism, maxInFlight)
+	}
+
+	// Results are cached, so a second batch does not go upstream
+	if _, found := c.Get("India"); !found {
+		t.Fatalf("expected India to be cached")
+	}
+	results, err = svc.BatchLookup([]models.BatchItem{{Name: "India"}, {Code: "DEU"}}, true)
+	if err != nil || results[0].Country.Name != "India" || results[1].Country.Name != "Germany" {
+		t.Fatalf("unexpected atomic results %+v, %v", results, err)
+	}
+	if calls["/name/india"] != 1 || calls["/alpha/deu"] != 0 {
+		t.Fatalf("expected cached lookups, got %v", calls)
+	}
+
+	// An all-or-nothing batch fails on its failed item
+	_, err = svc.BatchLookup([]models.BatchItem{{Name: "India"}, {Name: "Atlantis"}}, true)
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Index != 1 || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected a BatchError on item 1, got %v", err)
+	}
+
+	if _, err := svc.BatchLookup(nil, false); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for an empty batch, got %v", err)
+	}
+	if _, err := svc.BatchLookup(make([]models.BatchItem, MaxBatchSize+1), false); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for an oversized batch, got %v", err)
+	}
+}
+
+func TestExportCountries(t *testing.T) {
+	countries := indexTestCountries()
+	var fail bool
+	var remote int
+	ts := indexTestServer(t, &countries, &fail, &remote)
+	defer ts.Close()
+
+	origAll := allURL
+	allURL = ts.URL + "/all"
+	defer func() { allURL = origAll }()
+
+	svc := NewService(cache.NewCache(10))
+
+	var names []string
+	err := svc.ExportCountries(func(meta models.CountryMetadata) error {
+		names = append(names, meta.Name)
+		return nil
+	})
+	if err != nil || strings.Join(names, ",") != "Germany,Switzerland" {
+		t.Fatalf("unexpected export %v, %v", names, err)
+	}
+
+	// The first error stops the export
+	stop := errors.New("stop")
+	calls := 0
+	err = svc.ExportCountries(func(models.CountryMetadata) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Fatalf("expected the export to stop after one country, got %d calls and %v", calls, err)
+	}
+}
+
+func TestSearchCountries_Freshness(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]models.Country{{Name: models.Name{Common: "Testland"}, CCA3: "TST"}})
+	}))
+	defer ts.Close()
+
+	orig := baseURL
+	baseURL = ts.URL + "/"
+	defer func() { baseURL = orig }()
+
+	svc := NewService(cache.NewCacheWithTTL(10, time.Hour))
+
+	// A fetched country is fresh for the TTL of the cache
+	before := time.Now()
+	var fetched Freshness
+	if _, err := svc.SearchCountries("Testland", WithFreshness(&fetched)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fetched.Modified.Before(before) || fetched.Modified.After(time.Now()) {
+		t.Fatalf("expected modified time of the fetch, got %v", fetched.Modified)
+	}
+	if !fetched.Expires.Equal(fetched.Modified.Add(time.Hour)) {
+		t.Fatalf("expected expiry one hour after %v, got %v", fetched.Modified, fetched.Expires)
+	}
+
+	// A cache hit reports the time the entry was stored
+	var cached Freshness
+	if _, err := svc.SearchCountries("Testland", WithFreshness(&cached)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cached.Modified.Equal(fetched.Modified) || !cached.Expires.Equal(fetched.Expires) {
+		t.Fatalf("expected cached freshness %+v, got %+v", fetched, cached)
+	}
+}
+
+func TestLocalIndex_Freshness(t *testing.T) {
+	countries := indexTestCountries()
+	var fail bool
+	var remote int
+	ts := indexTestServer(t, &countries, &fail, &remote)
+	defer ts.Close()
+
+	orig := allURL
+	allURL = ts.URL + "/all"
+	defer func() { allURL = orig }()
+
+	// Countries answered from the index are fresh until the next refresh
+	svc := NewService(cache.NewCache(10))
+	svc.localIndex.interval.Store(int64(6 * time.Hour))
+	if err := svc.RefreshIndex(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var f Freshness
+	if _, err := svc.SearchByCode("DEU", WithFreshness(&f)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.Modified.IsZero() || !f.Expires.Equal(f.Modified.Add(6*time.Hour)) {
+		t.Fatalf("expected freshness until the next refresh, got %+v", f)
+	}
+}
+
+func TestWarmAndPurgeCache(t *testing.T) {
+	countries := indexTestCountries()
+	var fail bool
+	var remote int
+	ts := indexTestServer(t, &countries, &fail, &remote)
+	defer ts.Close()
+
+	origAll := allURL
+	allURL = ts.URL + "/all"
+	defer func() { allURL = origAll }()
+
+	svc := NewService(cache.NewCache(20))
+	warmed, err := svc.WarmCache()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if warmed != 2 {
+		t.Fatalf("expected 2 warmed countries, got %d", warmed)
+	}
+	// each country is cached under its name, two codes and its capital
+	if stats := svc.CacheStats(); stats.Size != 8 || stats.Capacity != 20 {
+		t.Fatalf("unexpected stats after warming: %+v", stats)
+	}
+
+	if res, err := svc.SearchByCapital("bern"); err != nil || res.Name != "Switzerland" {
+		t.Fatalf("expected Switzerland by capital, got %+v, %v", res, err)
+	}
+	if stats := svc.CacheStats(); stats.Hits != 1 {
+		t.Fatalf("expected the lookup to hit the cache, got %+v", stats)
+	}
+	// the index was loaded with the same countries
+	if page, err := svc.ListCountries(ListQuery{Query: "german"}); err != nil || page.Total != 2 {
+		t.Fatalf("expected the warmed index to answer, got %+v, %v", page, err)
+	}
+
+	if purged := svc.PurgeCache(); purged != 8 {
+		t.Fatalf("expected 8 purged entries, got %d", purged)
+	}
+	if stats := svc.CacheStats(); stats.Size != 0 {
+		t.Fatalf("expected an empty cache, got %+v", stats)
+	}
+
+	fail = true
+	if _, err := svc.WarmCache(); !errors.Is(err, ErrUpstreamUnavailable) {
+		t.Fatalf("expected ErrUpstreamUnavailable, got %v", err)
+	}
+	if remote != 0 {
+		t.Fatalf("expected no lookups upstream, got %d", remote)
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&NotFoundError{Query: "Atlantis"}, CodeNotFound},
+		{&BatchError{Index: 1, Err: &ValidationError{Field: "name", Reason: "must not be empty"}}, CodeInvalidInput},
+		{upstreamError(errors.New("connection refused")), CodeUpstreamUnavailable},
+		{fmt.Errorf("%w: status 500", ErrUpstreamBadResponse), CodeUpstreamBadResponse},
+		{fmt.Errorf("%w: deadline", ErrTimeout), CodeTimeout},
+		{errors.New("boom"), CodeInternal},
+	}
+
+	for _, tt := range tests {
+		if got := ErrorCode(tt.err); got != tt.want {
+			t.Fatalf("ErrorCode(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestPublicMessage(t *testing.T) {
+	tests := []struct {
+		err          error
+		want         string
+		wantRedacted bool
+	}{
+		{&NotFoundError{Query: "Atlantis"}, "country not found: Atlantis", false},
+		{&ValidationError{Field: "name", Reason: "must not be empty"}, (&ValidationError{Field: "name", Reason: "must not be empty"}).Error(), false},
+		{upstreamError(errors.New(`Get "https://restcountries.com/v3.1/all": connection refused`)), "the country data source is unavailable", true},
+		{fmt.Errorf("%w: status 500", ErrUpstreamBadResponse), "the country data source sent an invalid response", true},
+		{fmt.Errorf("%w: deadline", ErrTimeout), "the country data source did not answer in time", true},
+		{errors.New("boom"), "internal server error", true},
+	}
+
+	for _, tt := range tests {
+		got, redacted := PublicMessage(tt.err)
+		if got != tt.want || redacted != tt.wantRedacted {
+			t.Fatalf("PublicMessage(%v) = %q, %v, want %q, %v", tt.err, got, redacted, tt.want, tt.wantRedacted)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Limits applied to free-text search parameters. The longest official
+// country name is well under MaxNameLength characters.
+const (
+	MaxNameLength   = 100
+	MaxSymbolLength = 10
+)
+
+// ValidationError describes why a single input parameter was rejected. It
+// matches ErrInvalidInput under errors.Is.
+type ValidationError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%v: %s %s", ErrInvalidInput, e.Field, e.Reason)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// ValidateName checks a country or place name supplied by a client. Names
+// must be present, at most MaxNameLength characters long and made only of
+// letters, spaces and the punctuation found in real country names, so that
+// they cannot alter the upstream request path.
+func ValidateName(field, name string) error {
+	if strings.TrimSpace(name) == "" {
+		return &ValidationError{Field: field, Reason: "is required"}
+	}
+	if utf8.RuneCountInString(name) > MaxNameLength {
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("must be at most %d characters", MaxNameLength)}
+	}
+	for _, r := range name {
+		if !isNameRune(r) {
+			return &ValidationError{Field: field, Reason: fmt.Sprintf("contains invalid character %q", r)}
+		}
+	}
+	return nil
+}
+
+// ValidateCode checks a country code supplied by a client: two or three
+// letters (cca2, cca3, cioc) or three digits (ccn3).
+func ValidateCode(field, code string) error {
+	if code == "" {
+		return &ValidationError{Field: field, Reason: "is required"}
+	}
+	if !isLetterCode(code) && !isNumericCode(code) {
+		return &ValidationError{Field: field, Reason: "must be 2 or 3 letters or 3 digits"}
+	}
+	return nil
+}
+
+// ValidateCurrency checks a currency query, which is either a three-letter
+// ISO 4217 code or a currency symbol such as "€" or "R$".
+func ValidateCurrency(field, currency string) error {
+	if currency == "" {
+		return &ValidationError{Field: field, Reason: "is required"}
+	}
+	if utf8.RuneCountInString(currency) > MaxSymbolLength {
+		return &ValidationError{Field: field, Reason: fmt.Sprintf("must be at most %d characters", MaxSymbolLength)}
+	}
+	for _, r := range currency {
+		if !unicode.IsLetter(r) && !unicode.IsSymbol(r) && !unicode.Is(unicode.Mn, r) && r != '.' {
+			return &ValidationError{Field: field, Reason: fmt.Sprintf("contains invalid character %q", r)}
+		}
+	}
+	return nil
+}
+
+// IsCurrencyCode reports whether s has the shape of an ISO 4217 code.
+func IsCurrencyCode(s string) bool {
+	return len(s) == 3 && isLetterCode(s)
+}
+
+func isLetterCode(code string) bool {
+	if len(code) != 2 && len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func isNumericCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isNameRune(r rune) bool {
+	if unicode.IsLetter(r) || unicode.Is(unicode.Mn, r) {
+		return true
+	}
+	switch r {
+	case ' ', '-', '\'', '’', '.', ',', '(', ')', '&':
+		return true
+	}
+	return false
+}
@@ -1 +1,13 @@
 package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// NewRequestID returns a random 128-bit identifier encoded as hex.
+func NewRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}